@@ -0,0 +1,201 @@
+package sqroot
+
+import (
+	"math"
+	"math/big"
+	"strings"
+	"sync"
+)
+
+// AtBase comes from the Number interface.
+func (n *FiniteNumber) AtBase(posit, base int) int {
+	if base < 2 || base > 16 {
+		panic("base must be between 2 and 16")
+	}
+	if posit < 0 || n.IsZero() {
+		return -1
+	}
+	return n.baseExpansion(base).At(n, posit, base)
+}
+
+// basesMu guards the bases field of every FiniteNumber so that the field
+// can be allocated lazily.
+var basesMu sync.Mutex
+
+func (n *FiniteNumber) baseExpansion(base int) *baseExpansion {
+	basesMu.Lock()
+	defer basesMu.Unlock()
+	if n.bases == nil {
+		n.bases = make(map[int]*baseExpansion)
+	}
+	result, ok := n.bases[base]
+	if !ok {
+		result = &baseExpansion{}
+		n.bases[base] = result
+	}
+	return result
+}
+
+// ToStringBase returns the value of n written in base. The returned string
+// has at most digits digits after the radix point. If n terminates in base
+// before that, ToStringBase returns only the digits up to where it
+// terminates. ToStringBase truncates rather than rounds and uses lowercase
+// letters for digits above 9. For example, ToStringBase(2, 10) on 0.1
+// returns "0.0001100110". ToStringBase panics if base is not between 2 and
+// 16 or if digits is negative.
+func (n *FiniteNumber) ToStringBase(base, digits int) string {
+	if base < 2 || base > 16 {
+		panic("base must be between 2 and 16")
+	}
+	if digits < 0 {
+		panic("digits must be non-negative")
+	}
+	x := truncatedRat(n, endOf(n))
+	intPart := new(big.Int).Quo(x.Num(), x.Denom())
+	fracPart := new(big.Rat).Sub(x, new(big.Rat).SetInt(intPart))
+	fracDigits, _ := toBase(fracPart, big.NewInt(int64(base)), 0, digits)
+	if len(fracDigits) == 0 {
+		return intPart.Text(base)
+	}
+	var builder strings.Builder
+	builder.WriteString(intPart.Text(base))
+	builder.WriteByte('.')
+	for _, digit := range fracDigits {
+		builder.WriteByte(kBaseDigits[digit])
+	}
+	return builder.String()
+}
+
+const (
+	kBaseDigits = "0123456789abcdef"
+
+	// The number of extra decimal digits computeBaseDigits reads before
+	// deciding that a Number lies exactly on a digit boundary in a base.
+	kBaseBoundaryDigits = 1000
+)
+
+// baseExpansion memoizes the significant digits of a Number in a
+// particular base.
+type baseExpansion struct {
+	mu     sync.Mutex
+	digits []int
+	exact  bool
+}
+
+func (b *baseExpansion) At(n Number, posit, base int) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for !b.exact && len(b.digits) <= posit {
+		b.digits, b.exact = computeBaseDigits(
+			n, base, max(posit+1, 2*len(b.digits)))
+	}
+	if posit >= len(b.digits) {
+		return -1
+	}
+	return b.digits[posit]
+}
+
+// computeBaseDigits returns the first count significant digits of n in
+// base. If n has fewer than count significant digits in base,
+// computeBaseDigits returns all of them and exact is true.
+// computeBaseDigits uses more and more decimal digits of n until the
+// bounds of n agree on the first count digits in base. If the bounds still
+// disagree after kBaseBoundaryDigits extra decimal digits, such as for 1/3
+// in base 3, computeBaseDigits takes n to lie exactly on the digit boundary
+// between the bounds and returns the digits of that boundary.
+func computeBaseDigits(n Number, base, count int) (digits []int, exact bool) {
+	bigBase := big.NewInt(int64(base))
+	sigDigits := int(float64(count)*math.Log10(float64(base))) + kGuardDigits
+	limit := sigDigits + kBaseBoundaryDigits
+	for ; ; sigDigits *= 2 {
+		lower := truncatedRat(n, sigDigits)
+		exp := baseExponent(lower, bigBase)
+		lowerDigits, terminated := toBase(lower, bigBase, exp, count)
+		if n.At(sigDigits) == -1 {
+			return lowerDigits, terminated
+		}
+		upper := scaleByPowerOf10(big.NewRat(1, 1), n.Exponent()-sigDigits)
+		upper.Add(upper, lower)
+		upperDigits, _ := toBase(upper, bigBase, exp, count)
+		if commonPrefix(lowerDigits, upperDigits, count) {
+			return lowerDigits, false
+		}
+		if sigDigits >= limit {
+			return boundaryDigits(upper, bigBase, count), true
+		}
+	}
+}
+
+// boundaryDigits returns the first count significant digits of upper in
+// base without any trailing zeros.
+func boundaryDigits(upper *big.Rat, base *big.Int, count int) []int {
+	digits, _ := toBase(upper, base, baseExponent(upper, base), count)
+	for len(digits) > 0 && digits[len(digits)-1] == 0 {
+		digits = digits[:len(digits)-1]
+	}
+	return digits
+}
+
+// baseExponent returns exp such that base^(exp-1) <= x < base^exp. x must
+// be positive.
+func baseExponent(x *big.Rat, base *big.Int) int {
+	exp := 0
+	power := big.NewRat(1, 1)
+	bigBase := new(big.Rat).SetInt(base)
+	for x.Cmp(power) >= 0 {
+		power.Mul(power, bigBase)
+		exp++
+	}
+	power.Quo(power, bigBase)
+	for x.Cmp(power) < 0 {
+		power.Quo(power, bigBase)
+		exp--
+	}
+	return exp
+}
+
+// toBase returns the first count digits of x/base^exp in base. If the
+// expansion has fewer than count digits, toBase returns all of them and
+// terminated is true.
+func toBase(x *big.Rat, base *big.Int, exp, count int) (
+	digits []int, terminated bool) {
+	var power big.Int
+	power.Exp(base, big.NewInt(int64(max(exp, -exp))), nil)
+	scale := new(big.Rat).SetInt(&power)
+	remainder := new(big.Rat).Set(x)
+	if exp >= 0 {
+		remainder.Quo(remainder, scale)
+	} else {
+		remainder.Mul(remainder, scale)
+	}
+	bigBase := new(big.Rat).SetInt(base)
+	var digit big.Int
+	for range count {
+		if remainder.Sign() == 0 {
+			return digits, true
+		}
+		remainder.Mul(remainder, bigBase)
+		digit.Quo(remainder.Num(), remainder.Denom())
+		remainder.Sub(remainder, new(big.Rat).SetInt(&digit))
+		digits = append(digits, int(digit.Int64()))
+	}
+	return digits, remainder.Sign() == 0
+}
+
+// commonPrefix returns true if x and y have the same first count digits.
+// Missing digits count as zero.
+func commonPrefix(x, y []int, count int) bool {
+	for i := range count {
+		if digitOrZero(x, i) != digitOrZero(y, i) {
+			return false
+		}
+	}
+	return true
+}
+
+func digitOrZero(digits []int, posit int) int {
+	if posit >= len(digits) {
+		return 0
+	}
+	return digits[posit]
+}
@@ -0,0 +1,128 @@
+package sqroot
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAtBaseHex(t *testing.T) {
+	f := new(big.Float).SetPrec(2000).SetInt64(2)
+	f.Sqrt(f)
+	f.SetMantExp(f, 4*400)
+	hexDigits, _ := f.Int(nil)
+	expected := hexDigits.Text(16)
+	n := Sqrt(2)
+	for i := 0; i < 400; i++ {
+		assert.Equal(t, int(expected[i]-'0'), hexValue(n.AtBase(i, 16)))
+	}
+	assert.Equal(t, 1, n.AtBase(0, 16))
+	assert.Equal(t, 6, n.AtBase(1, 16))
+	assert.Equal(t, 10, n.AtBase(2, 16))
+}
+
+func TestAtBaseOutOfOrder(t *testing.T) {
+	n := Sqrt(2)
+	assert.Equal(t, 10, n.AtBase(2, 16))
+	assert.Equal(t, 1, n.AtBase(0, 2))
+	assert.Equal(t, 0, n.AtBase(1, 2))
+	assert.Equal(t, 1, n.AtBase(2, 2))
+	assert.Equal(t, 6, n.AtBase(1, 16))
+}
+
+func TestAtBaseDigitBoundary(t *testing.T) {
+	n := NewNumberFromBigRat(big.NewRat(1, 3))
+	assert.Equal(t, 1, n.AtBase(0, 3))
+	assert.Equal(t, -1, n.AtBase(1, 3))
+	assert.Equal(t, 3, n.AtBase(0, 10))
+	n = NewNumberFromBigRat(big.NewRat(10, 3))
+	assert.Equal(t, 1, n.AtBase(0, 3))
+	assert.Equal(t, 0, n.AtBase(1, 3))
+	assert.Equal(t, 1, n.AtBase(2, 3))
+	assert.Equal(t, -1, n.AtBase(3, 3))
+	n = NewNumberFromBigRat(big.NewRat(5, 54))
+	assert.Equal(t, 3, n.AtBase(0, 6))
+	assert.Equal(t, 2, n.AtBase(1, 6))
+	assert.Equal(t, -1, n.AtBase(2, 6))
+}
+
+func TestAtBaseTen(t *testing.T) {
+	n := SqrtRat(1, 300)
+	for i := 0; i < 100; i++ {
+		assert.Equal(t, n.At(i), n.AtBase(i, 10))
+	}
+}
+
+func TestAtBaseSmall(t *testing.T) {
+
+	// 1/32 is 0.00001 in base 2 and 0.08 in base 16.
+	n := NewNumberFromBigRat(big.NewRat(1, 32))
+	assert.Equal(t, 1, n.AtBase(0, 2))
+	assert.Equal(t, -1, n.AtBase(1, 2))
+	assert.Equal(t, 8, n.AtBase(0, 16))
+	assert.Equal(t, -1, n.AtBase(1, 16))
+}
+
+func TestAtBaseFinite(t *testing.T) {
+	n, _ := NewFiniteNumber([]int{2, 5}, 2)
+	assert.Equal(t, 1, n.AtBase(0, 2))
+	assert.Equal(t, 1, n.AtBase(1, 2))
+	assert.Equal(t, 0, n.AtBase(2, 2))
+	assert.Equal(t, 0, n.AtBase(3, 2))
+	assert.Equal(t, 1, n.AtBase(4, 2))
+	assert.Equal(t, -1, n.AtBase(5, 2))
+	assert.Equal(t, 2, n.AtBase(0, 3))
+	assert.Equal(t, 2, n.AtBase(1, 3))
+	assert.Equal(t, 1, n.AtBase(2, 3))
+	assert.Equal(t, -1, n.AtBase(3, 3))
+}
+
+func TestAtBaseBadArgs(t *testing.T) {
+	n := Sqrt(2)
+	assert.Equal(t, -1, n.AtBase(-1, 16))
+	assert.Equal(t, -1, zeroNumber.AtBase(0, 16))
+	assert.Panics(t, func() { n.AtBase(0, 1) })
+	assert.Panics(t, func() { n.AtBase(0, 17) })
+}
+
+func hexValue(digit int) int {
+	if digit >= 10 {
+		return int('a'-'0') + digit - 10
+	}
+	return digit
+}
+
+func TestToStringBase(t *testing.T) {
+	n, _ := NewFiniteNumber([]int{2, 5}, 2)
+	assert.Equal(t, "11001", n.ToStringBase(2, 10))
+	assert.Equal(t, "19", n.ToStringBase(16, 10))
+	n, _ = NewFiniteNumber([]int{1}, 0)
+	assert.Equal(t, "0.0001100110", n.ToStringBase(2, 10))
+	assert.Equal(t, "0.1999", n.ToStringBase(16, 4))
+	assert.Equal(t, "0", n.ToStringBase(16, 0))
+	n, _ = NewFiniteNumber([]int{2, 5}, 1)
+	assert.Equal(t, "10.1", n.ToStringBase(2, 10))
+	assert.Equal(t, "2.8", n.ToStringBase(16, 10))
+	assert.Equal(t, "0", zeroNumber.ToStringBase(2, 10))
+}
+
+func TestToStringBaseBigRat(t *testing.T) {
+	n := Sqrt(2).WithSignificant(30)
+	x := truncatedRat(n, 30)
+	for _, base := range []int64{2, 16} {
+		scale := new(big.Int).Exp(big.NewInt(base), big.NewInt(40), nil)
+		scaled := new(big.Rat).Mul(x, new(big.Rat).SetInt(scale))
+		digits := new(big.Int).Quo(scaled.Num(), scaled.Denom()).Text(int(base))
+		expected := "1." + strings.TrimRight(digits[1:], "0")
+		assert.Equal(t, expected, n.ToStringBase(int(base), 40))
+	}
+}
+
+func TestToStringBaseBadArgs(t *testing.T) {
+	n, _ := NewFiniteNumber([]int{2, 5}, 2)
+	assert.Panics(t, func() { n.ToStringBase(1, 10) })
+	assert.Panics(t, func() { n.ToStringBase(17, 10) })
+	assert.Panics(t, func() { n.ToStringBase(10, -1) })
+}
@@ -0,0 +1,329 @@
+package sqroot
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"os"
+)
+
+const (
+	kBinaryVersion = 1
+	kFileMagic     = "SQRT"
+)
+
+// SaveNumber saves n to the file at path so that LoadNumber can read it
+// back. SaveNumber computes all the digits of n before writing.
+func SaveNumber(path string, n *FiniteNumber) error {
+	data, err := n.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("SaveNumber: %s: %w", path, err)
+	}
+	contents := append([]byte(kFileMagic), data...)
+	if err := os.WriteFile(path, contents, 0644); err != nil {
+		return fmt.Errorf("SaveNumber: %s: %w", path, err)
+	}
+	return nil
+}
+
+// LoadNumber reads a FiniteNumber from the file at path that SaveNumber
+// wrote. The digits of the returned FiniteNumber are all in memory.
+func LoadNumber(path string) (*FiniteNumber, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("LoadNumber: %s: %w", path, err)
+	}
+	data, ok := bytes.CutPrefix(contents, []byte(kFileMagic))
+	if !ok {
+		return nil, fmt.Errorf("LoadNumber: %s: not a saved Number", path)
+	}
+	var result FiniteNumber
+	if err := result.UnmarshalBinary(data); err != nil {
+		return nil, fmt.Errorf("LoadNumber: %s: %w", path, err)
+	}
+	return &result, nil
+}
+
+// MarshalBinary encodes n as bytes. It computes all the digits of n. The
+// encoding stores two digits per byte.
+func (n *FiniteNumber) MarshalBinary() ([]byte, error) {
+	digits := n.mantissa.allDigits()
+	result := []byte{kBinaryVersion}
+	result = binary.AppendVarint(result, int64(n.exponent))
+	result = binary.AppendUvarint(result, uint64(len(digits)))
+	return appendPackedDigits(result, digits), nil
+}
+
+// BinarySize returns the length of what MarshalBinary would return for n
+// without building the encoding. Like MarshalBinary, BinarySize computes
+// all the digits of n.
+func (n *FiniteNumber) BinarySize() int {
+	return marshaledSize(len(n.mantissa.allDigits()), n.exponent)
+}
+
+// UnmarshalBinary sets n to the FiniteNumber that data encodes. data
+// comes from MarshalBinary. Call UnmarshalBinary only on a newly
+// declared FiniteNumber that no other goroutine is using.
+func (n *FiniteNumber) UnmarshalBinary(data []byte) error {
+	if len(data) == 0 || data[0] != kBinaryVersion {
+		return errors.New("UnmarshalBinary: unsupported format")
+	}
+	data = data[1:]
+	exponent, size := binary.Varint(data)
+	if size <= 0 || exponent < math.MinInt || exponent > math.MaxInt {
+		return errors.New("UnmarshalBinary: bad exponent")
+	}
+	data = data[size:]
+	digits, err := unpackDigits(data)
+	if err != nil {
+		return fmt.Errorf("UnmarshalBinary: %w", err)
+	}
+	count := len(digits)
+	n.bases = nil
+	n.source = nil
+	if count == 0 {
+		n.mantissa = mantissa{}
+		n.exponent = 0
+		return nil
+	}
+	n.mantissa = mantissa{spec: &staticSpec{data: digits}}
+	n.exponent = int(exponent)
+	return nil
+}
+
+// ExportState comes from the Number interface.
+func (n *FiniteNumber) ExportState() ([]byte, error) {
+	if n.source == nil {
+		return nil, errors.New(
+			"ExportState: Number is not the result of a root function")
+	}
+	count, complete := n.mantissa.Known()
+	digits := n.mantissa.spec.FirstN(count)
+	result := []byte{kBinaryVersion}
+	result = binary.AppendUvarint(result, uint64(n.source.degree))
+	result = appendBigInt(result, &n.source.num)
+	result = appendBigInt(result, &n.source.denom)
+	result = binary.AppendVarint(result, int64(n.exponent))
+	if complete {
+		result = append(result, 1)
+	} else {
+		result = append(result, 0)
+	}
+	result = binary.AppendUvarint(result, uint64(len(digits)))
+	return appendPackedDigits(result, digits), nil
+}
+
+// RestoreNumberState returns the Number whose state ExportState exported
+// as data. The returned Number already has all the digits that were
+// computed when ExportState was called and computes the rest of its
+// digits picking up where the exported Number left off. Passing the
+// returned Number to ExportState works.
+func RestoreNumberState(data []byte) (Number, error) {
+	state, err := decodeRootState(data)
+	if err != nil {
+		return nil, fmt.Errorf("RestoreNumberState: %w", err)
+	}
+	result, err := state.Restore()
+	if err != nil {
+		return nil, fmt.Errorf("RestoreNumberState: %w", err)
+	}
+	return result, nil
+}
+
+// rootSource describes the root that a Number is the result of.
+type rootSource struct {
+	num        big.Int
+	denom      big.Int
+	degree     int
+	newManager func() rootManager
+}
+
+func newRootSource(
+	num, denom *big.Int, newManager func() rootManager) *rootSource {
+	result := &rootSource{
+		newManager: newManager, degree: newManager().Degree()}
+	result.num.Set(num)
+	result.denom.Set(denom)
+	return result
+}
+
+type rootState struct {
+	source   *rootSource
+	exponent int
+	complete bool
+	digits   []int8
+}
+
+func decodeRootState(data []byte) (*rootState, error) {
+	if len(data) == 0 || data[0] != kBinaryVersion {
+		return nil, errors.New("unsupported format")
+	}
+	data = data[1:]
+	degree, size := binary.Uvarint(data)
+	if size <= 0 {
+		return nil, errors.New("bad degree")
+	}
+	data = data[size:]
+	var newManager func() rootManager
+	switch degree {
+	case 2:
+		newManager = newSqrtManager
+	case 3:
+		newManager = newCubeRootManager
+	default:
+		return nil, errors.New("bad degree")
+	}
+	var num, denom big.Int
+	data, ok := readBigInt(data, &num)
+	if !ok || num.Sign() <= 0 {
+		return nil, errors.New("bad radican")
+	}
+	data, ok = readBigInt(data, &denom)
+	if !ok || denom.Sign() <= 0 {
+		return nil, errors.New("bad radican")
+	}
+	exponent, size := binary.Varint(data)
+	if size <= 0 || exponent < math.MinInt || exponent > math.MaxInt {
+		return nil, errors.New("bad exponent")
+	}
+	data = data[size:]
+	if len(data) == 0 || data[0] > 1 {
+		return nil, errors.New("bad completion flag")
+	}
+	complete := data[0] == 1
+	digits, err := unpackDigits(data[1:])
+	if err != nil {
+		return nil, err
+	}
+	return &rootState{
+		source:   newRootSource(&num, &denom, newManager),
+		exponent: int(exponent),
+		complete: complete,
+		digits:   digits,
+	}, nil
+}
+
+// Restore returns the Number that s describes. Rather than computing the
+// digits in s again, Restore derives the state of the digit by digit root
+// computation from the radican and the digits in s.
+func (s *rootState) Restore() (Number, error) {
+	manager := s.source.newManager()
+	groups, exp, consumed := computeGroupsFromRationalAt(
+		&s.source.num,
+		&s.source.denom,
+		manager.Base(new(big.Int)),
+		len(s.digits))
+	if exp != s.exponent {
+		return nil, errors.New("bad exponent")
+	}
+	root := new(big.Int)
+	for _, digit := range s.digits {
+		root.Mul(root, ten).Add(root, big.NewInt(int64(digit)))
+	}
+	degree := big.NewInt(int64(s.source.degree))
+	remainder := new(big.Int).Exp(root, degree, nil)
+	upper := new(big.Int).Add(root, one)
+	upper.Exp(upper, degree, nil)
+	if remainder.Cmp(consumed) > 0 || upper.Cmp(consumed) <= 0 {
+		return nil, errors.New("digits don't match radican")
+	}
+	remainder.Sub(consumed, remainder)
+	if s.complete {
+		if remainder.Sign() != 0 || groups(new(big.Int)) != nil {
+			return nil, errors.New("digits don't match radican")
+		}
+		return &FiniteNumber{
+			mantissa: mantissa{spec: &staticSpec{data: s.digits}},
+			exponent: s.exponent,
+			source:   s.source,
+		}, nil
+	}
+	incr := new(big.Int)
+	manager.Resume(root, incr)
+	digits := resumeRootDigits(groups, manager, incr, remainder)
+	return opaqueNumber(&FiniteNumber{
+		mantissa: mantissa{spec: newMemoizeSpecFrom(s.digits, digits, nil)},
+		exponent: s.exponent,
+		source:   s.source,
+	}), nil
+}
+
+func appendBigInt(data []byte, x *big.Int) []byte {
+	bytes := x.Bytes()
+	data = binary.AppendUvarint(data, uint64(len(bytes)))
+	return append(data, bytes...)
+}
+
+func readBigInt(data []byte, x *big.Int) ([]byte, bool) {
+	length, size := binary.Uvarint(data)
+	if size <= 0 || length > uint64(len(data[size:])) {
+		return nil, false
+	}
+	data = data[size:]
+	x.SetBytes(data[:length])
+	return data[length:], true
+}
+
+// appendPackedDigits appends digits to data two digits per byte.
+func appendPackedDigits(data []byte, digits []int8) []byte {
+	for i := 0; i < len(digits); i += 2 {
+		packed := byte(digits[i]) << 4
+		if i+1 < len(digits) {
+			packed |= byte(digits[i+1])
+		}
+		data = append(data, packed)
+	}
+	return data
+}
+
+// unpackDigits reads a digit count followed by digits packed two per byte
+// and checks that the digits are valid mantissa digits.
+func unpackDigits(data []byte) ([]int8, error) {
+	count, size := binary.Uvarint(data)
+	if size <= 0 {
+		return nil, errors.New("bad digit count")
+	}
+	data = data[size:]
+
+	// Check count against the bytes remaining before doing any arithmetic
+	// on count so that a corrupt count can't overflow.
+	if count > uint64(2*len(data)) || (count+1)/2 != uint64(len(data)) {
+		return nil, errors.New("bad digit count")
+	}
+	digits := make([]int8, 0, 2*len(data))
+	for _, packed := range data {
+		digits = append(digits, int8(packed>>4), int8(packed&0xf))
+	}
+	if count%2 == 1 {
+		if digits[count] != 0 {
+			return nil, errors.New("bad padding")
+		}
+		digits = digits[:count]
+	}
+	if !validDigits8(digits) || (count > 0 && digits[0] == 0) {
+		return nil, errors.New("bad digits")
+	}
+	return digits, nil
+}
+
+// marshaledSize returns the length of what MarshalBinary returns for a
+// FiniteNumber with count digits and the given exponent.
+func marshaledSize(count, exponent int) int {
+	if count == 0 {
+		exponent = 0
+	}
+	return 1 + len(binary.AppendVarint(nil, int64(exponent))) +
+		len(binary.AppendUvarint(nil, uint64(count))) + (count+1)/2
+}
+
+func validDigits8(x []int8) bool {
+	for _, d := range x {
+		if digitOutOfRange(int(d)) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,265 @@
+package sqroot
+
+import (
+	"encoding/binary"
+	"math"
+	"math/big"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSaveAndLoadNumber(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sqrt2")
+	n := Sqrt(2).WithSignificant(5000)
+	assert.NoError(t, SaveNumber(path, n))
+	loaded, err := LoadNumber(path)
+	assert.NoError(t, err)
+	assert.Equal(t, n.Exponent(), loaded.Exponent())
+	assert.Equal(t, DigitsToString(n), DigitsToString(loaded))
+	assert.Equal(t, 5000, len(DigitsToString(loaded)))
+	count, complete := loaded.DigitsKnown()
+	assert.Equal(t, 5000, count)
+	assert.True(t, complete)
+}
+
+func TestSaveAndLoadNumberOddAndNegative(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "small")
+	n := SqrtRat(1, 300000).WithSignificant(7)
+	assert.NoError(t, SaveNumber(path, n))
+	loaded, err := LoadNumber(path)
+	assert.NoError(t, err)
+	assert.Equal(t, n.Exact(), loaded.Exact())
+	assert.Equal(t, -2, loaded.Exponent())
+}
+
+func TestSaveAndLoadZero(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "zero")
+	assert.NoError(t, SaveNumber(path, zeroNumber))
+	loaded, err := LoadNumber(path)
+	assert.NoError(t, err)
+	assert.True(t, loaded.IsZero())
+	assert.Equal(t, "0", loaded.String())
+}
+
+func TestLoadNumberErrors(t *testing.T) {
+	dir := t.TempDir()
+	missing := filepath.Join(dir, "missing")
+	_, err := LoadNumber(missing)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	assert.Contains(t, err.Error(), missing)
+
+	garbage := filepath.Join(dir, "garbage")
+	assert.NoError(t, os.WriteFile(garbage, []byte("hello"), 0644))
+	_, err = LoadNumber(garbage)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), garbage)
+
+	corrupt := filepath.Join(dir, "corrupt")
+	assert.NoError(t, os.WriteFile(corrupt, []byte("SQRT\x01\x02\x03\x1a"), 0644))
+	_, err = LoadNumber(corrupt)
+	assert.Error(t, err)
+}
+
+func TestSaveNumberError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "no", "such", "dir")
+	err := SaveNumber(path, Sqrt(2).WithSignificant(10))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), path)
+}
+
+func TestMarshalBinary(t *testing.T) {
+	n, _ := NewFiniteNumber([]int{3, 1, 7}, 3)
+	data, err := n.MarshalBinary()
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{1, 6, 3, 0x31, 0x70}, data)
+	var decoded FiniteNumber
+	assert.NoError(t, decoded.UnmarshalBinary(data))
+	assert.Equal(t, "317", decoded.String())
+}
+
+func TestBinarySize(t *testing.T) {
+	small, _ := NewFiniteNumber([]int{3, 1, 7}, 3)
+	var zero FiniteNumber
+	numbers := []*FiniteNumber{
+		small,
+		&zero,
+		Sqrt(2).WithSignificant(1000),
+		Sqrt(2).WithSignificant(1001),
+		SqrtRat(1, 300000).WithSignificant(127),
+		Sqrt(2).withExponent(-200).WithSignificant(128),
+	}
+	for _, n := range numbers {
+		data, err := n.MarshalBinary()
+		assert.NoError(t, err)
+		assert.Equal(t, len(data), n.BinarySize())
+	}
+	assert.Equal(t, 3, zero.BinarySize())
+}
+
+func TestUnmarshalBinaryErrors(t *testing.T) {
+	var n FiniteNumber
+	assert.Error(t, n.UnmarshalBinary(nil))
+	assert.Error(t, n.UnmarshalBinary([]byte{2, 6, 3, 0x31, 0x70}))
+	assert.Error(t, n.UnmarshalBinary([]byte{1, 6, 3, 0x31}))
+	assert.Error(t, n.UnmarshalBinary([]byte{1, 6, 3, 0x31, 0x71}))
+	assert.Error(t, n.UnmarshalBinary([]byte{1, 6, 3, 0x01, 0x70}))
+	assert.Error(t, n.UnmarshalBinary([]byte{1, 6, 3, 0x3a, 0x70}))
+}
+
+func TestUnmarshalBinaryCorruptCount(t *testing.T) {
+	maxCount := binary.AppendUvarint([]byte{1, 6}, math.MaxUint64)
+	var n FiniteNumber
+	assert.Error(t, n.UnmarshalBinary(maxCount))
+	assert.Error(t, n.UnmarshalBinary(append(maxCount, 0x31, 0x70)))
+	huge := binary.AppendUvarint([]byte{1, 6}, 1<<62)
+	assert.Error(t, n.UnmarshalBinary(append(huge, 0x31)))
+	assert.Error(t, n.UnmarshalBinary([]byte{1, 6, 5, 0x31, 0x70}))
+	assert.Error(t, n.UnmarshalBinary([]byte{1, 6, 1, 0x31, 0x70}))
+
+	// A count whose varint encoding is cut off.
+	assert.Error(t, n.UnmarshalBinary([]byte{1, 6, 0xff, 0xff}))
+
+	path := filepath.Join(t.TempDir(), "corrupt")
+	contents := append([]byte(kFileMagic), maxCount...)
+	assert.NoError(t, os.WriteFile(path, contents, 0644))
+	_, err := LoadNumber(path)
+	assert.Error(t, err)
+}
+
+func TestWithSignificantForBytes(t *testing.T) {
+	numbers := []Number{Sqrt(2), SqrtRat(1, 300000), Sqrt(2).withExponent(200)}
+	for _, n := range numbers {
+		for _, maxBytes := range []int{4, 5, 6, 100, 1024, 1025} {
+			truncated := n.WithSignificantForBytes(maxBytes)
+			data, err := truncated.MarshalBinary()
+			assert.NoError(t, err)
+			assert.LessOrEqual(t, len(data), maxBytes)
+			count := len(DigitsToString(truncated))
+			data, err = n.WithSignificant(count + 1).MarshalBinary()
+			assert.NoError(t, err)
+			assert.Greater(t, len(data), maxBytes)
+		}
+	}
+}
+
+func TestWithSignificantForBytesSmall(t *testing.T) {
+	n := Sqrt(2)
+	assert.True(t, n.WithSignificantForBytes(3).IsZero())
+	assert.True(t, n.WithSignificantForBytes(0).IsZero())
+	assert.True(t, n.WithSignificantForBytes(-1).IsZero())
+	assert.Equal(t, "1.414", n.WithSignificantForBytes(5).String())
+	assert.Equal(t, "317", Sqrt(100489).WithSignificantForBytes(100).String())
+}
+
+func TestExportAndRestoreState(t *testing.T) {
+	numbers := []Number{
+		Sqrt(2), SqrtRat(5, 7), CubeRoot(2), CubeRootRat(1000, 3), Sqrt(1000003)}
+	for _, n := range numbers {
+		n.At(499)
+		data, err := n.ExportState()
+		assert.NoError(t, err)
+		restored, err := RestoreNumberState(data)
+		assert.NoError(t, err)
+		count, _ := restored.DigitsKnown()
+		assert.GreaterOrEqual(t, count, 500)
+		assert.Equal(t, n.Exponent(), restored.Exponent())
+		assert.Equal(
+			t,
+			DigitsToString(n.WithEnd(2000)),
+			DigitsToString(restored.WithEnd(2000)))
+	}
+}
+
+func TestExportStateNothingComputed(t *testing.T) {
+	data, err := CubeRootRat(2, 3).ExportState()
+	assert.NoError(t, err)
+	restored, err := RestoreNumberState(data)
+	assert.NoError(t, err)
+	assert.Equal(t, CubeRootRat(2, 3).String(), restored.String())
+}
+
+func TestExportStateTwice(t *testing.T) {
+	n := Sqrt(3)
+	n.At(150)
+	data, err := n.ExportState()
+	assert.NoError(t, err)
+	restored, err := RestoreNumberState(data)
+	assert.NoError(t, err)
+	restored.At(350)
+	data, err = restored.ExportState()
+	assert.NoError(t, err)
+	restored, err = RestoreNumberState(data)
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		DigitsToString(n.WithEnd(1000)),
+		DigitsToString(restored.WithEnd(1000)))
+}
+
+func TestExportStateExact(t *testing.T) {
+	data, err := Sqrt(100489).ExportState()
+	assert.NoError(t, err)
+	restored, err := RestoreNumberState(data)
+	assert.NoError(t, err)
+	assert.IsType(t, &FiniteNumber{}, restored)
+	assert.Equal(t, "317", restored.String())
+	assert.True(t, restored.IsExact())
+}
+
+func TestUnmarshalBinaryIntoRestored(t *testing.T) {
+	data, err := Sqrt(100489).ExportState()
+	assert.NoError(t, err)
+	restored, err := RestoreNumberState(data)
+	assert.NoError(t, err)
+	n := restored.(*FiniteNumber)
+	other, _ := NewFiniteNumber([]int{2, 5}, 1)
+	encoded, err := other.MarshalBinary()
+	assert.NoError(t, err)
+	assert.NoError(t, n.UnmarshalBinary(encoded))
+	assert.Equal(t, "2.5", n.String())
+	_, err = n.ExportState()
+	assert.Error(t, err)
+}
+
+func TestExportStateErrors(t *testing.T) {
+	_, err := Sqrt(2).WithSignificant(10).ExportState()
+	assert.Error(t, err)
+	_, err = NewNumberFromBigRat(big.NewRat(1, 3)).ExportState()
+	assert.Error(t, err)
+	_, err = zeroNumber.ExportState()
+	assert.Error(t, err)
+}
+
+func TestRestoreNumberStateErrors(t *testing.T) {
+	n := Sqrt(2)
+	n.At(10)
+	data, err := n.ExportState()
+	assert.NoError(t, err)
+	_, err = RestoreNumberState(nil)
+	assert.Error(t, err)
+	_, err = RestoreNumberState(data[:len(data)-1])
+	assert.Error(t, err)
+
+	// Change the radican from 2 to 3.
+	bad := slices.Clone(data)
+	bad[3] = 3
+	_, err = RestoreNumberState(bad)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "RestoreNumberState")
+
+	// Change the degree to 4.
+	bad = slices.Clone(data)
+	bad[1] = 4
+	_, err = RestoreNumberState(bad)
+	assert.Error(t, err)
+
+	// Change a digit.
+	bad = slices.Clone(data)
+	bad[len(bad)-1]++
+	_, err = RestoreNumberState(bad)
+	assert.Error(t, err)
+}
@@ -8,11 +8,16 @@ var (
 	one                  = big.NewInt(1)
 	two                  = big.NewInt(2)
 	six                  = big.NewInt(6)
+	five                 = big.NewInt(5)
 	ten                  = big.NewInt(10)
+	twenty               = big.NewInt(20)
+	thirty               = big.NewInt(30)
+	sixty                = big.NewInt(60)
 	fortyFive            = big.NewInt(45)
 	fiftyFour            = big.NewInt(54)
 	oneHundred           = big.NewInt(100)
 	oneHundredSeventyOne = big.NewInt(171)
+	threeHundred         = big.NewInt(300)
 	oneThousand          = big.NewInt(1000)
 )
 
@@ -20,10 +25,24 @@ type rootManager interface {
 	Next(incr *big.Int)
 	NextDigit(incr *big.Int)
 	Base(result *big.Int) *big.Int
+	Degree() int
+
+	// Resume sets up this manager as if it had just computed the digits of
+	// root and stores the increment for the next digit in incr.
+	Resume(root, incr *big.Int)
 }
 
 func computeGroupsFromRational(num, denom, base *big.Int) (
 	groups func(result *big.Int) *big.Int, exp int) {
+	groups, exp, _ = computeGroupsFromRationalAt(num, denom, base, 0)
+	return
+}
+
+// computeGroupsFromRationalAt works like computeGroupsFromRational except
+// that the returned groups function starts after the first count groups.
+// consumed is the integer formed by those first count groups.
+func computeGroupsFromRationalAt(num, denom, base *big.Int, count int) (
+	groups func(result *big.Int) *big.Int, exp int, consumed *big.Int) {
 	num = new(big.Int).Set(num)
 	denom = new(big.Int).Set(denom)
 	base = new(big.Int).Set(base)
@@ -39,6 +58,10 @@ func computeGroupsFromRational(num, denom, base *big.Int) (
 		exp++
 		denom.Mul(denom, base)
 	}
+	var power big.Int
+	num.Mul(num, power.Exp(base, big.NewInt(int64(count)), nil))
+	consumed = new(big.Int)
+	consumed.DivMod(num, denom, num)
 	groups = func(result *big.Int) *big.Int {
 		if num.Sign() == 0 {
 			return nil
@@ -50,6 +73,26 @@ func computeGroupsFromRational(num, denom, base *big.Int) (
 	return
 }
 
+// computeGroupsFromDigits returns the base 100 groups of the mantissa that
+// digits generates. digits returns -1 when there are no more digits. exp is
+// the base 10 exponent of the mantissa; groupExp is the base 100 exponent.
+func computeGroupsFromDigits(digits func() int, exp int) (
+	groups func(result *big.Int) *big.Int, groupExp int) {
+	if exp%2 != 0 {
+		digits = firstAndThen(0, digits)
+		exp++
+	}
+	groups = func(result *big.Int) *big.Int {
+		high := digits()
+		if high == -1 {
+			return nil
+		}
+		low := max(digits(), 0)
+		return result.SetInt64(int64(10*high + low))
+	}
+	return groups, exp / 2
+}
+
 func groupsToDigits(groups func(result *big.Int) *big.Int) func() int {
 	var nextGroupHolder big.Int
 	return func() int {
@@ -64,9 +107,17 @@ func groupsToDigits(groups func(result *big.Int) *big.Int) func() int {
 func computeRootDigits(
 	radicanGroups func(result *big.Int) *big.Int,
 	manager rootManager) func() int {
+	return resumeRootDigits(radicanGroups, manager, big.NewInt(1), new(big.Int))
+}
+
+// resumeRootDigits works like computeRootDigits except that it resumes
+// the computation with the given increment and remainder. manager must
+// already be set up for the digits computed so far.
+func resumeRootDigits(
+	radicanGroups func(result *big.Int) *big.Int,
+	manager rootManager,
+	incr, remainder *big.Int) func() int {
 	base := manager.Base(new(big.Int))
-	incr := big.NewInt(1)
-	remainder := big.NewInt(0)
 	var nextGroupHolder big.Int
 	return func() int {
 		nextGroup := radicanGroups(&nextGroupHolder)
@@ -88,6 +139,53 @@ func computeRootDigits(
 	}
 }
 
+// exactRoot returns true if the degree root of num/denom has a finite
+// number of digits.
+func exactRoot(num, denom *big.Int, degree int) bool {
+	value := new(big.Rat).SetFrac(num, denom)
+	_, ok := intRoot(value.Num(), degree)
+	if !ok {
+		return false
+	}
+	denomRoot, ok := intRoot(value.Denom(), degree)
+	if !ok {
+		return false
+	}
+	var remainder big.Int
+	for _, factor := range []*big.Int{two, five} {
+		for {
+			var quotient big.Int
+			quotient.QuoRem(denomRoot, factor, &remainder)
+			if remainder.Sign() != 0 {
+				break
+			}
+			denomRoot = &quotient
+		}
+	}
+	return denomRoot.Cmp(one) == 0
+}
+
+// intRoot returns the degree root of x rounded down and true if that
+// root is exact. x must be non-negative.
+func intRoot(x *big.Int, degree int) (*big.Int, bool) {
+	bigDegree := big.NewInt(int64(degree))
+	bigDegreeMinusOne := big.NewInt(int64(degree - 1))
+	var power big.Int
+	root := new(big.Int).Lsh(one, uint(x.BitLen()/degree+1))
+	for {
+		var next big.Int
+		power.Exp(root, bigDegreeMinusOne, nil)
+		next.Quo(x, &power)
+		next.Add(&next, power.Mul(root, bigDegreeMinusOne))
+		next.Quo(&next, bigDegree)
+		if next.Cmp(root) >= 0 {
+			break
+		}
+		root = &next
+	}
+	return root, power.Exp(root, bigDegree, nil).Cmp(x) == 0
+}
+
 type sqrtManager struct {
 }
 
@@ -107,6 +205,16 @@ func (s sqrtManager) Base(result *big.Int) *big.Int {
 	return result.Set(oneHundred)
 }
 
+func (s sqrtManager) Degree() int {
+	return 2
+}
+
+func (s sqrtManager) Resume(root, incr *big.Int) {
+
+	// incr = (10*root+1)^2 - (10*root)^2 = 20*root + 1
+	incr.Mul(root, twenty).Add(incr, one)
+}
+
 type cubeRootManager struct {
 	incr2 big.Int
 }
@@ -134,3 +242,18 @@ func (c *cubeRootManager) NextDigit(incr *big.Int) {
 func (c *cubeRootManager) Base(result *big.Int) *big.Int {
 	return result.Set(oneThousand)
 }
+
+func (c *cubeRootManager) Degree() int {
+	return 3
+}
+
+func (c *cubeRootManager) Resume(root, incr *big.Int) {
+
+	// incr = (10*root+1)^3 - (10*root)^3 = 300*root^2 + 30*root + 1
+	var temp big.Int
+	incr.Mul(root, threeHundred).Mul(incr, root)
+	incr.Add(incr, temp.Mul(root, thirty)).Add(incr, one)
+
+	// incr2 = 6*(10*root+1) = 60*root + 6
+	c.incr2.Mul(root, sixty).Add(&c.incr2, six)
+}
@@ -370,6 +370,21 @@ func ExamplePrint_positions() {
 	// 200  70109 55997 16059 70274
 }
 
+func ExamplePrintN() {
+
+	// Find the square root of 2.
+	n := sqroot.Sqrt(2)
+
+	fmt.Printf("10^%d *\n", n.Exponent())
+	sqroot.PrintN(n, 120, sqroot.DigitsPerRow(40))
+	fmt.Println()
+	// Output:
+	// 10^1 *
+	//   0.14142 13562 37309 50488 01688 72420 96980 78569
+	// 40  67187 53769 48073 17667 97379 90732 47846 21070
+	// 80  38850 38753 43276 41572 73501 38462 30912 29702
+}
+
 func ExampleFiniteNumber_At() {
 
 	// sqrt(7) = 0.264575131106459...*10^1
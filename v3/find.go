@@ -3,6 +3,7 @@ package sqroot
 import (
 	"iter"
 	"slices"
+	"sync"
 )
 
 // Matches returns all the 0 based positions in s where pattern is found.
@@ -10,6 +11,154 @@ func Matches(s Sequence, pattern []int) iter.Seq[int] {
 	return matches(s, slices.Clone(pattern))
 }
 
+// MatchesClass works like Matches except that each element of classes is
+// a set of acceptable digits for the corresponding position of the
+// pattern. For example, classes of {{0, 1}, {8, 9}} match wherever a 0 or
+// 1 is followed by an 8 or 9. An empty class matches nothing.
+func MatchesClass(s Sequence, classes [][]int) iter.Seq[int] {
+	accept := make([][10]bool, len(classes))
+	for i, class := range classes {
+		for _, digit := range class {
+			if !digitOutOfRange(digit) {
+				accept[i][digit] = true
+			}
+		}
+	}
+	return func(yield func(index int) bool) {
+		if len(accept) == 0 {
+			for index := range s.All() {
+				if !yield(index) {
+					return
+				}
+			}
+			return
+		}
+		for index, window := range Windows(s, len(accept)) {
+			if classesMatch(accept, window) && !yield(index) {
+				return
+			}
+		}
+	}
+}
+
+// MatchesN works like Matches except that it yields at most n matches. If
+// n is zero or negative, MatchesN yields nothing. Unlike Matches, MatchesN
+// stops searching s once it finds n matches.
+func MatchesN(s Sequence, pattern []int, n int) iter.Seq[int] {
+	seq := Matches(s, pattern)
+	return func(yield func(index int) bool) {
+		if n <= 0 {
+			return
+		}
+		count := 0
+		for index := range seq {
+			if !yield(index) {
+				return
+			}
+			count++
+			if count == n {
+				return
+			}
+		}
+	}
+}
+
+// MatchesWithMinGap works like Matches except that it skips any match that
+// is less than minGap positions after the previously reported match. The
+// first match is always reported.
+func MatchesWithMinGap(s Sequence, pattern []int, minGap int) iter.Seq[int] {
+	seq := Matches(s, pattern)
+	return func(yield func(index int) bool) {
+		last := -1
+		for index := range seq {
+			if last != -1 && index-last < minGap {
+				continue
+			}
+			if !yield(index) {
+				return
+			}
+			last = index
+		}
+	}
+}
+
+// Windows returns each zero based position in s along with the size digits
+// that start at that position. The returned slice of digits is reused
+// between iterations so it is valid only until the next iteration. If s
+// is finite, Windows stops when fewer than size digits remain. Like the
+// search functions, Windows ignores any gaps in the positions of s.
+// Windows panics if size is not positive.
+func Windows(s Sequence, size int) iter.Seq2[int, []int] {
+	if size <= 0 {
+		panic("size must be positive")
+	}
+	return func(yield func(index int, window []int) bool) {
+		positions := make([]int, 0, size)
+		window := make([]int, 0, size)
+		for index, value := range s.All() {
+			if len(window) == size {
+				positions = append(positions[:0], positions[1:]...)
+				window = append(window[:0], window[1:]...)
+			}
+			positions = append(positions, index)
+			window = append(window, value)
+			if len(window) == size && !yield(positions[0], window) {
+				return
+			}
+		}
+	}
+}
+
+// EveryNth returns the zero based position and value of every kth digit of
+// s, that is the digits of s at positions 0, k, 2k, 3k and so on. Positions
+// that s does not have are skipped. If s is finite, EveryNth stops at the
+// end of s. EveryNth panics if k is not positive.
+func EveryNth(s Sequence, k int) iter.Seq2[int, int] {
+	if k <= 0 {
+		panic("k must be positive")
+	}
+	return func(yield func(index, value int) bool) {
+		for index, value := range s.All() {
+			if index%k == 0 && !yield(index, value) {
+				return
+			}
+		}
+	}
+}
+
+// Diff returns each zero based position where a and b differ along with
+// the digit of a and the digit of b at that position. Diff uses -1 for the
+// digit of a sequence that has no digit at that position. Diff yields the
+// positions in increasing order.
+func Diff(a, b FiniteSequence) iter.Seq2[int, [2]int] {
+	return func(yield func(index int, values [2]int) bool) {
+		aIter, bIter := a.Iterator(), b.Iterator()
+		aDigit, aOk := aIter()
+		bDigit, bOk := bIter()
+		for aOk || bOk {
+			switch {
+			case !bOk || aOk && aDigit.Position < bDigit.Position:
+				if !yield(aDigit.Position, [2]int{aDigit.Value, -1}) {
+					return
+				}
+				aDigit, aOk = aIter()
+			case !aOk || bDigit.Position < aDigit.Position:
+				if !yield(bDigit.Position, [2]int{-1, bDigit.Value}) {
+					return
+				}
+				bDigit, bOk = bIter()
+			default:
+				if aDigit.Value != bDigit.Value && !yield(
+					aDigit.Position, [2]int{aDigit.Value, bDigit.Value}) {
+					return
+				}
+				aDigit, aOk = aIter()
+				bDigit, bOk = bIter()
+			}
+		}
+	}
+}
+
 // BackwardMatches returns all the 0 based positions in s where pattern is
 // found from last to first.
 func BackwardMatches(s FiniteSequence, pattern []int) iter.Seq[int] {
@@ -40,6 +189,62 @@ func FindFirst(s Sequence, pattern []int) int {
 	return collectFirst(matches(s, pattern))
 }
 
+// FindFirstRelative works like FindFirst except that the returned index is
+// relative to the position of the first digit in s rather than the start
+// of the mantissa. For example, if s is n.WithStart(800) and FindFirst
+// would return 853, FindFirstRelative returns 53. Like FindFirst,
+// FindFirstRelative returns -1 if pattern is not found only if s has a
+// finite number of digits.
+func FindFirstRelative(s Sequence, pattern []int) int {
+	index := FindFirst(s, pattern)
+	if index == -1 {
+		return -1
+	}
+	return index - startOf(s)
+}
+
+// FirstIndexOf returns the zero based index of the first digit in s that
+// equals digit. FirstIndexOf works like FindFirst with a single digit
+// pattern, but it is faster. If s is finite and has no such digit,
+// FirstIndexOf returns -1. If s is infinite and has no such digit,
+// FirstIndexOf runs forever.
+func FirstIndexOf(s Sequence, digit int) int {
+	for index, value := range s.All() {
+		if value == digit {
+			return index
+		}
+	}
+	return -1
+}
+
+// NthIndexOf returns the zero based index of the nth digit in s that
+// equals digit where n is 1 based. NthIndexOf(s, digit, 1) is the same as
+// FirstIndexOf(s, digit). If s has fewer than n such digits or if n is
+// not positive, NthIndexOf returns -1.
+func NthIndexOf(s FiniteSequence, digit, n int) int {
+	if n <= 0 {
+		return -1
+	}
+	for index, value := range s.All() {
+		if value != digit {
+			continue
+		}
+		n--
+		if n == 0 {
+			return index
+		}
+	}
+	return -1
+}
+
+// ContainsSubstring returns true if pattern is found in the digits of s
+// that have positions less than limit. ContainsSubstring stops as soon as
+// it finds a match. If s is finite, limit can be math.MaxInt to search
+// all of s.
+func ContainsSubstring(s Sequence, pattern []int, limit int) bool {
+	return find(s.WithEnd(limit), pattern)() != -1
+}
+
 // FindFirstN works like FindFirst but it finds the first n matches and
 // returns the zero based index of each match. If s has a finite
 // number of digits, FindFirstN may return fewer than n matches.
@@ -56,6 +261,55 @@ func FindAll(s FiniteSequence, pattern []int) []int {
 	return slices.Collect(matches(s, pattern))
 }
 
+// FindAllParallel works like FindAll except that it splits s into chunks
+// and searches each chunk on its own goroutine. workers is the number of
+// chunks. If workers is less than 1, FindAllParallel uses 1 worker.
+// Neighboring chunks overlap by len(pattern)-1 digits so that matches
+// spanning a chunk boundary are still found. Like FindAll, the returned
+// indexes are in increasing order.
+func FindAllParallel(s FiniteSequence, pattern []int, workers int) []int {
+	pattern = slices.Clone(pattern)
+	start, end := startOf(s), endOf(s)
+	if start >= end {
+		return nil
+	}
+	workers = max(1, min(workers, end-start))
+	overlap := max(0, len(pattern)-1)
+	results := make([][]int, workers)
+	var wg sync.WaitGroup
+	for i := range workers {
+		chunkStart := start + i*(end-start)/workers
+		chunkEnd := start + (i+1)*(end-start)/workers
+		chunk := s.FiniteWithStart(chunkStart).WithEnd(
+			overlapEnd(s, chunkEnd, overlap))
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = slices.Collect(matches(chunk, pattern))
+		}()
+	}
+	wg.Wait()
+	return slices.Concat(results...)
+}
+
+// overlapEnd returns the position just past the first overlap digits of s
+// at or after end. overlapEnd counts digits rather than positions so that
+// chunks of a FiniteSequence with gaps still overlap by overlap digits.
+func overlapEnd(s FiniteSequence, end, overlap int) int {
+	result := end
+	if overlap == 0 {
+		return result
+	}
+	for index := range s.FiniteWithStart(end).All() {
+		result = index + 1
+		overlap--
+		if overlap == 0 {
+			break
+		}
+	}
+	return result
+}
+
 // FindLast finds the zero based index of the last match of pattern in s.
 // FindLast returns -1 if pattern is not found in s. pattern is a sequence of
 // digits between 0 and 9.
@@ -78,6 +332,73 @@ func FindR(s FiniteSequence, pattern []int) func() int {
 	return findR(s, patternReverse(pattern))
 }
 
+// FindPalindromes returns the start and length of every maximal palindrome
+// in s that is at least minLength digits long. A palindrome is maximal if
+// it can't be extended by one digit on both sides and still be a
+// palindrome. The palindromes are yielded in order of their centers.
+// Like the other search functions, FindPalindromes ignores any gaps in
+// the positions of s.
+func FindPalindromes(s FiniteSequence, minLength int) iter.Seq2[int, int] {
+	return func(yield func(start, length int) bool) {
+		var positions, digits []int
+		for index, value := range s.All() {
+			positions = append(positions, index)
+			digits = append(digits, value)
+		}
+		odd, even := palindromeRadii(digits)
+		for i := range digits {
+			if even[i] > 0 && 2*even[i] >= minLength {
+				if !yield(positions[i-even[i]], 2*even[i]) {
+					return
+				}
+			}
+			if 2*odd[i]-1 >= minLength {
+				if !yield(positions[i-odd[i]+1], 2*odd[i]-1) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// palindromeRadii uses Manacher's algorithm to find the longest palindrome
+// centered at each digit. odd[i] is the radius, including digits[i], of
+// the longest odd length palindrome centered at digits[i]. even[i] is the
+// radius of the longest even length palindrome centered between
+// digits[i-1] and digits[i].
+func palindromeRadii(digits []int) (odd, even []int) {
+	n := len(digits)
+	odd = make([]int, n)
+	even = make([]int, n)
+	for i, l, r := 0, 0, -1; i < n; i++ {
+		k := 1
+		if i <= r {
+			k = min(odd[l+r-i], r-i+1)
+		}
+		for i-k >= 0 && i+k < n && digits[i-k] == digits[i+k] {
+			k++
+		}
+		odd[i] = k
+		if i+k-1 > r {
+			l, r = i-k+1, i+k-1
+		}
+	}
+	for i, l, r := 0, 0, -1; i < n; i++ {
+		k := 0
+		if i <= r {
+			k = min(even[l+r-i+1], r-i+1)
+		}
+		for i+k < n && i-k-1 >= 0 && digits[i+k] == digits[i-k-1] {
+			k++
+		}
+		even[i] = k
+		if i+k-1 > r {
+			l, r = i-k, i+k-1
+		}
+	}
+	return
+}
+
 func findR(s FiniteSequence, patternInReverse []int) func() int {
 	if len(patternInReverse) == 0 {
 		return zeroPattern(s.Reverse())
@@ -92,6 +413,15 @@ func find(s Sequence, pattern []int) func() int {
 	return kmp(s.Iterator(), pattern, false)
 }
 
+func classesMatch(accept [][10]bool, window []int) bool {
+	for i, digit := range window {
+		if !accept[i][digit] {
+			return false
+		}
+	}
+	return true
+}
+
 func matches(s Sequence, pattern []int) iter.Seq[int] {
 	return func(yield func(index int) bool) {
 		gen := find(s, pattern)
@@ -1,6 +1,8 @@
 package sqroot
 
 import (
+	"math"
+	"slices"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -25,6 +27,110 @@ func TestFindAll(t *testing.T) {
 	assert.Equal(t, []int{2, 12, 22, 32}, hits)
 }
 
+func TestFindAllParallel(t *testing.T) {
+	n := Sqrt(2).WithSignificant(5000)
+	patterns := [][]int{{1, 4}, {0, 0}, {9}, {1, 2, 3}, nil}
+	for _, pattern := range patterns {
+		expected := FindAll(n, pattern)
+		for _, workers := range []int{-1, 0, 1, 2, 3, 7, 16} {
+			assert.Equal(t, expected, FindAllParallel(n, pattern, workers))
+		}
+	}
+}
+
+func TestFindAllParallelBoundary(t *testing.T) {
+
+	// n = 0.3434343434...
+	n, _ := NewNumberForTesting(nil, []int{3, 4}, 0)
+	s := n.WithEnd(10)
+
+	// With 2 workers, chunks are [0, 5) and [5, 10) so the match at
+	// position 4 spans the boundary.
+	expected := []int{0, 2, 4, 6}
+	assert.Equal(t, expected, FindAll(s, []int{3, 4, 3, 4}))
+	assert.Equal(t, expected, FindAllParallel(s, []int{3, 4, 3, 4}, 2))
+	assert.Equal(t, expected, FindAllParallel(s, []int{3, 4, 3, 4}, 5))
+}
+
+func TestFindAllParallelWithStart(t *testing.T) {
+	s := fakeNumber().WithStart(13).WithEnd(57)
+	assert.Equal(
+		t, []int{22, 32, 42, 52}, FindAllParallel(s, []int{3, 4}, 4))
+	assert.Equal(t, FindAll(s, nil), FindAllParallel(s, nil, 4))
+}
+
+func TestFindAllParallelEmpty(t *testing.T) {
+	var n FiniteNumber
+	assert.Empty(t, FindAllParallel(&n, []int{5}, 4))
+	assert.Empty(t, FindAllParallel(fakeNumber().WithEnd(0), []int{5}, 4))
+	assert.Empty(
+		t, FindAllParallel(fakeNumber().WithEnd(10), []int{5, 7}, 4))
+}
+
+func TestFindGappedFilter(t *testing.T) {
+	var pb PositionsBuilder
+	s := pb.Add(0).Add(10).Add(20).Build().Filter(Sqrt(2))
+	pattern := []int{1, 3}
+	assert.Equal(t, []int{0}, slices.Collect(Matches(s, pattern)))
+	assert.Equal(t, 0, FindFirst(s, pattern))
+	assert.Equal(t, 0, FindLast(s, pattern))
+	assert.Equal(t, []int{0}, FindAll(s, pattern))
+	for _, workers := range []int{1, 2, 3} {
+		assert.Equal(t, []int{0}, FindAllParallel(s, pattern, workers))
+	}
+}
+
+func TestFindOverlappingAcrossGaps(t *testing.T) {
+
+	// 1 at every tenth position and 2 everywhere else.
+	digits := make([]int, 41)
+	for i := range digits {
+		digits[i] = 2
+		if i%10 == 0 {
+			digits[i] = 1
+		}
+	}
+	n, _ := NewFiniteNumber(digits, 0)
+	var pb PositionsBuilder
+	s := pb.Add(0).Add(10).Add(20).Add(30).Add(40).Build().Filter(n)
+	pattern := []int{1, 1, 1}
+	expected := []int{0, 10, 20}
+	assert.Equal(t, expected, slices.Collect(Matches(s, pattern)))
+	assert.Equal(t, expected, FindAll(s, pattern))
+	assert.Equal(t, 0, FindFirst(s, pattern))
+	assert.Equal(t, 20, FindLast(s, pattern))
+	assert.Equal(t, []int{20, 10, 0}, FindLastN(s, pattern, 5))
+	for _, workers := range []int{1, 2, 3, 4, 5} {
+		assert.Equal(t, expected, FindAllParallel(s, pattern, workers))
+	}
+}
+
+func TestFindAllParallelGappedFilter(t *testing.T) {
+	var pb PositionsBuilder
+	for i := 0; i < 3000; i += 7 {
+		pb.AddRange(i, i+3)
+	}
+	s := pb.Build().Filter(Sqrt(2))
+	var positions, digits []int
+	for index, value := range s.All() {
+		positions = append(positions, index)
+		digits = append(digits, value)
+	}
+	for _, pattern := range [][]int{{1, 4}, {2, 7, 1}, {0, 0, 0, 0}, nil} {
+		var expected []int
+		for i := range digits {
+			if i+len(pattern) <= len(digits) &&
+				slices.Equal(digits[i:i+len(pattern)], pattern) {
+				expected = append(expected, positions[i])
+			}
+		}
+		assert.Equal(t, expected, FindAll(s, pattern))
+		for _, workers := range []int{2, 3, 7, 16} {
+			assert.Equal(t, expected, FindAllParallel(s, pattern, workers))
+		}
+	}
+}
+
 func TestMatches(t *testing.T) {
 	s := fakeNumber().WithSignificant(40)
 	pattern := []int{3, 4}
@@ -38,6 +144,39 @@ func TestMatches(t *testing.T) {
 	assert.Equal(t, []int{2, 12, 22, 32}, hits)
 }
 
+func TestMatchesClass(t *testing.T) {
+	s := Sqrt(2).WithEnd(60)
+	classes := [][]int{{0, 1}, {8, 9}}
+	assert.Equal(t, []int{13, 29, 42}, slices.Collect(MatchesClass(s, classes)))
+	assert.Equal(
+		t,
+		slices.Collect(Matches(s, []int{1, 4, 2})),
+		slices.Collect(MatchesClass(s, [][]int{{1}, {4}, {2}})))
+	anyDigit := []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+	assert.Equal(
+		t,
+		[]int{0, 10, 20, 30},
+		slices.Collect(
+			MatchesClass(fakeNumber().WithEnd(40), [][]int{{1}, anyDigit, {3}})))
+	assert.Empty(t, slices.Collect(MatchesClass(s, [][]int{{1}, {}})))
+	assert.Empty(t, slices.Collect(MatchesClass(s, [][]int{{10, -1}})))
+	assert.Equal(
+		t,
+		[]int{3, 4, 5},
+		slices.Collect(MatchesClass(fakeNumber().WithStart(3).WithEnd(6), nil)))
+}
+
+func TestMatchesClassInfinite(t *testing.T) {
+	var hits []int
+	for index := range MatchesClass(fakeNumber(), [][]int{{9}, {0, 5}}) {
+		hits = append(hits, index)
+		if len(hits) == 3 {
+			break
+		}
+	}
+	assert.Equal(t, []int{8, 18, 28}, hits)
+}
+
 func TestBackwardMatches(t *testing.T) {
 	s := fakeNumber().WithSignificant(40)
 	pattern := []int{3, 4}
@@ -81,6 +220,18 @@ func TestFindFirst(t *testing.T) {
 	assert.Equal(t, 5, FindFirst(fakeNumber(), []int{6, 7, 8}))
 }
 
+func TestFindFirstRelative(t *testing.T) {
+	n := Sqrt(29)
+	assert.Equal(t, 853, FindFirst(n.WithStart(800), []int{8, 5}))
+	assert.Equal(t, 53, FindFirstRelative(n.WithStart(800), []int{8, 5}))
+	assert.Equal(t, 0, FindFirstRelative(fakeNumber().WithStart(13), []int{4}))
+	assert.Equal(
+		t, 7, FindFirstRelative(Between(20, 40).Filter(fakeNumber()), []int{8}))
+	assert.Equal(t, FindFirst(n, []int{8, 5}), FindFirstRelative(n, []int{8, 5}))
+	assert.Equal(
+		t, -1, FindFirstRelative(Sqrt(100489).WithStart(1), []int{3}))
+}
+
 func TestFindFirstNotThere(t *testing.T) {
 	assert.Equal(t, -1, FindFirst(Sqrt(100489), []int{5}))
 }
@@ -179,3 +330,254 @@ func intSliceFromString(s string) []int {
 	}
 	return result
 }
+
+func TestFindPalindromes(t *testing.T) {
+	n, _ := NewNumberForTesting(intSliceFromString("123454321"), []int{7}, 0)
+	var starts, lengths []int
+	for start, length := range FindPalindromes(n.WithEnd(12), 3) {
+		starts = append(starts, start)
+		lengths = append(lengths, length)
+	}
+	assert.Equal(t, []int{0, 9}, starts)
+	assert.Equal(t, []int{9, 3}, lengths)
+}
+
+func TestFindPalindromesEven(t *testing.T) {
+	n, _ := NewNumberForTesting(intSliceFromString("912213"), nil, 0)
+	var starts, lengths []int
+	for start, length := range FindPalindromes(n.WithEnd(6), 2) {
+		starts = append(starts, start)
+		lengths = append(lengths, length)
+	}
+	assert.Equal(t, []int{1}, starts)
+	assert.Equal(t, []int{4}, lengths)
+}
+
+func TestFindPalindromesWithStart(t *testing.T) {
+	n, _ := NewNumberForTesting(intSliceFromString("5123454321"), nil, 0)
+	var starts, lengths []int
+	for start, length := range FindPalindromes(n.WithStart(3).WithEnd(10), 3) {
+		starts = append(starts, start)
+		lengths = append(lengths, length)
+	}
+	assert.Equal(t, []int{3}, starts)
+	assert.Equal(t, []int{5}, lengths)
+}
+
+func TestFindPalindromesEarlyExit(t *testing.T) {
+	n, _ := NewNumberForTesting(intSliceFromString("1213121"), nil, 0)
+	count := 0
+	for range FindPalindromes(n.WithEnd(7), 1) {
+		count++
+		if count == 2 {
+			break
+		}
+	}
+	assert.Equal(t, 2, count)
+}
+
+func TestFindPalindromesNone(t *testing.T) {
+	count := 0
+	for range FindPalindromes(Sqrt(2).WithEnd(8), 4) {
+		count++
+	}
+	assert.Zero(t, count)
+}
+
+func TestMatchesWithMinGap(t *testing.T) {
+	s := Sqrt(2).WithEnd(1000)
+	all := FindAll(s, []int{1})
+	gapped := slices.Collect(MatchesWithMinGap(s, []int{1}, 50))
+	assert.Equal(t, all[0], gapped[0])
+	for i := 1; i < len(gapped); i++ {
+		assert.GreaterOrEqual(t, gapped[i]-gapped[i-1], 50)
+	}
+	last := -50
+	var expected []int
+	for _, index := range all {
+		if index-last >= 50 {
+			expected = append(expected, index)
+			last = index
+		}
+	}
+	assert.Equal(t, expected, gapped)
+}
+
+func TestMatchesWithMinGapSmall(t *testing.T) {
+	n := fakeNumber()
+	assert.Equal(
+		t,
+		FindFirstN(n, []int{1, 2}, 5),
+		collectFirstN(MatchesWithMinGap(n, []int{1, 2}, 1), 5))
+	assert.Equal(
+		t,
+		[]int{0, 20, 40},
+		collectFirstN(MatchesWithMinGap(n, []int{1, 2}, 11), 3))
+	assert.Equal(
+		t,
+		[]int{0, 10, 20},
+		collectFirstN(MatchesWithMinGap(n, []int{1, 2}, 10), 3))
+}
+
+func TestWindows(t *testing.T) {
+	var positions []int
+	var windows [][]int
+	for index, window := range Windows(Sqrt(2).WithEnd(10), 3) {
+		positions = append(positions, index)
+		windows = append(windows, slices.Clone(window))
+	}
+	assert.Equal(t, []int{0, 1, 2, 3, 4, 5, 6, 7}, positions)
+	assert.Equal(
+		t,
+		[][]int{
+			{1, 4, 1}, {4, 1, 4}, {1, 4, 2}, {4, 2, 1},
+			{2, 1, 3}, {1, 3, 5}, {3, 5, 6}, {5, 6, 2}},
+		windows)
+}
+
+func TestWindowsInfinite(t *testing.T) {
+	count := 0
+	for index, window := range Windows(fakeNumber().WithStart(5), 2) {
+		assert.Equal(t, 5+count, index)
+		assert.Equal(t, []int{(index + 1) % 10, (index + 2) % 10}, window)
+		count++
+		if count == 20 {
+			break
+		}
+	}
+	assert.Equal(t, 20, count)
+}
+
+func TestEveryNth(t *testing.T) {
+	var positions, values []int
+	for index, value := range EveryNth(Sqrt(2).WithEnd(55), 10) {
+		positions = append(positions, index)
+		values = append(values, value)
+	}
+	assert.Equal(t, []int{0, 10, 20, 30, 40, 50}, positions)
+	assert.Equal(t, []int{1, 3, 0, 9, 6, 4}, values)
+}
+
+func TestEveryNthInfinite(t *testing.T) {
+	count := 0
+	for index, value := range EveryNth(fakeNumber().WithStart(4), 3) {
+		assert.Equal(t, 6+3*count, index)
+		assert.Equal(t, (index+1)%10, value)
+		count++
+		if count == 20 {
+			break
+		}
+	}
+	assert.Equal(t, 20, count)
+	assert.Panics(t, func() { EveryNth(fakeNumber(), 0) })
+}
+
+func TestWindowsTooShort(t *testing.T) {
+	for range Windows(Sqrt(2).WithEnd(2), 3) {
+		t.Error("Expected no windows")
+	}
+}
+
+func TestWindowsPanics(t *testing.T) {
+	assert.Panics(t, func() { Windows(Sqrt(2), 0) })
+}
+
+func TestFirstIndexOf(t *testing.T) {
+	n := Sqrt(2)
+	assert.Equal(t, 14, FirstIndexOf(n, 9))
+	assert.Equal(t, FindFirst(n, []int{9}), FirstIndexOf(n, 9))
+	assert.Equal(t, 0, FirstIndexOf(n, 1))
+	assert.Equal(t, 16, FirstIndexOf(n.WithStart(15), 0))
+	assert.Equal(t, -1, FirstIndexOf(n.WithEnd(14), 9))
+	assert.Equal(t, -1, FirstIndexOf(Sqrt(100489), 5))
+	assert.Equal(t, 2, FirstIndexOf(Sqrt(100489), 7))
+}
+
+func TestNthIndexOf(t *testing.T) {
+	s := fakeNumber().WithEnd(35)
+	assert.Equal(t, 2, NthIndexOf(s, 3, 1))
+	assert.Equal(t, 12, NthIndexOf(s, 3, 2))
+	assert.Equal(t, 32, NthIndexOf(s, 3, 4))
+	assert.Equal(t, -1, NthIndexOf(s, 3, 5))
+	assert.Equal(t, 29, NthIndexOf(s, 0, 3))
+	assert.Equal(t, -1, NthIndexOf(s, 0, 4))
+	assert.Equal(t, -1, NthIndexOf(s, 3, 0))
+	assert.Equal(t, -1, NthIndexOf(s, 3, -1))
+	assert.Equal(t, 22, NthIndexOf(s.FiniteWithStart(5), 3, 2))
+	assert.Equal(t, 22, NthIndexOf(Between(20, 30).Filter(fakeNumber()), 3, 1))
+	assert.Equal(
+		t, FirstIndexOf(Sqrt(2), 9), NthIndexOf(Sqrt(2).WithEnd(100), 9, 1))
+}
+
+func TestMatchesN(t *testing.T) {
+	n := Sqrt(2)
+	assert.Equal(t, []int{0, 2}, slices.Collect(MatchesN(n, []int{1, 4}, 2)))
+	assert.Equal(
+		t,
+		FindFirstN(n, []int{1, 4}, 5),
+		slices.Collect(MatchesN(n, []int{1, 4}, 5)))
+	assert.Empty(t, slices.Collect(MatchesN(n, []int{1, 4}, 0)))
+	assert.Empty(t, slices.Collect(MatchesN(n, []int{1, 4}, -1)))
+	assert.Equal(
+		t, []int{0, 2}, slices.Collect(MatchesN(n.WithEnd(10), []int{1, 4}, 5)))
+}
+
+func TestContainsSubstring(t *testing.T) {
+	n := Sqrt(2)
+	assert.True(t, ContainsSubstring(n, []int{1, 4}, 10))
+	assert.True(t, ContainsSubstring(n, []int{5, 6}, 10))
+	assert.False(t, ContainsSubstring(n, []int{5, 6, 2, 3}, 10))
+	assert.False(t, ContainsSubstring(n, []int{9, 9}, 10))
+	assert.False(t, ContainsSubstring(n, []int{1, 4}, 1))
+	assert.True(t, ContainsSubstring(n.WithStart(2), []int{1, 4}, 10))
+	assert.False(t, ContainsSubstring(n.WithStart(3), []int{1, 4}, 10))
+	assert.True(t, ContainsSubstring(Sqrt(100489), []int{1, 7}, math.MaxInt))
+	assert.False(t, ContainsSubstring(Sqrt(100489), []int{7, 0}, math.MaxInt))
+}
+
+func TestDiff(t *testing.T) {
+
+	// sqrt(2) = 1.4142135623730950488...
+	perturbed, _ := NewNumberForTesting(
+		[]int{1, 4, 1, 4, 2, 1, 3, 5, 6, 2, 3, 7, 3, 1, 9, 5, 0},
+		[]int{4},
+		1)
+	var positions []int
+	var values [][2]int
+	for index, pair := range Diff(
+		Sqrt(2).WithEnd(20), perturbed.WithEnd(22)) {
+		positions = append(positions, index)
+		values = append(values, pair)
+	}
+	assert.Equal(t, []int{13, 18, 19, 20, 21}, positions)
+	assert.Equal(
+		t,
+		[][2]int{{0, 1}, {8, 4}, {8, 4}, {-1, 4}, {-1, 4}},
+		values)
+}
+
+func TestDiffStartAndGaps(t *testing.T) {
+	n := fakeNumber()
+	var positions []int
+	var values [][2]int
+	for index, pair := range Diff(
+		n.WithStart(2).WithEnd(6), UpTo(4).Filter(n)) {
+		positions = append(positions, index)
+		values = append(values, pair)
+	}
+	assert.Equal(t, []int{0, 1, 4, 5}, positions)
+	assert.Equal(t, [][2]int{{-1, 1}, {-1, 2}, {5, -1}, {6, -1}}, values)
+}
+
+func TestDiffSameAndEarlyExit(t *testing.T) {
+	n := Sqrt(2)
+	for range Diff(n.WithEnd(100), n.WithEnd(100)) {
+		assert.Fail(t, "Expected no differences")
+	}
+	count := 0
+	for range Diff(n.WithEnd(100), n.WithStart(50).WithEnd(100)) {
+		count++
+		break
+	}
+	assert.Equal(t, 1, count)
+}
@@ -2,37 +2,85 @@ package sqroot
 
 import (
 	"bufio"
+	"bytes"
 	"fmt"
 	"io"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 )
 
+// kColorReset is the ANSI escape sequence that ends highlighting.
+const kColorReset = "\x1b[0m"
+
 type printer struct {
 	rawPrinter
-	missingDigit rune
+	missingDigit  rune
+	skipEmptyRows bool
+	padLastRow    bool
+	highlight     Positions
+	color         string
+	bracketed     Positions
+	inBracket     bool
 }
 
 func newPrinter(
-	writer io.Writer, maxDigits int, settings *printerSettings) *printer {
+	writer io.Writer, start, maxDigits int, settings *printerSettings) *printer {
 	var result printer
-	result.Init(writer, maxDigits, settings)
+	result.Init(writer, start, maxDigits, settings)
 	result.missingDigit = settings.missingDigit
+	result.skipEmptyRows = settings.skipEmptyRows || result.rowStarter.CountOn()
+	result.padLastRow = settings.padLastRow
+	result.highlight = settings.highlight
+	result.color = settings.highlightColor
+	result.bracketed = settings.bracketed
 	return &result
 }
 
 func (p *printer) Consume(d Digit) {
+	bracketed := p.bracketed.contains(d.Position)
+	if p.inBracket && (!bracketed || p.index != d.Position || p.atSeparator()) {
+		p.closeBracket()
+	}
 	if p.index < d.Position {
-		if p.digitsPerRow > 0 && p.rowStarter.CountOn() {
+		if p.digitsPerRow > 0 && p.skipEmptyRows {
 			p.skipRowsFor(d.Position)
 		}
 		for p.index < d.Position {
 			p.rawPrinter.Consume(p.missingDigit)
 		}
 	}
+	if bracketed && !p.inBracket {
+		p.rawPrinter.consume('0'+rune(d.Value), "[", "")
+		p.inBracket = p.CanConsume()
+		return
+	}
+	if p.color != "" && p.highlight.contains(d.Position) {
+		p.rawPrinter.ConsumeWithColor('0'+rune(d.Value), p.color)
+		return
+	}
 	p.rawPrinter.Consume('0' + rune(d.Value))
 }
 
+func (p *printer) Finish() {
+	if p.inBracket {
+		p.closeBracket()
+	}
+	if p.padLastRow && p.digitsPerRow > 0 && p.index > 0 {
+		for p.CanConsume() && p.index%p.digitsPerRow != 0 {
+			p.rawPrinter.Consume(p.missingDigit)
+		}
+	}
+	p.rawPrinter.Finish()
+}
+
+func (p *printer) closeBracket() {
+	p.inBracket = false
+	if p.CanConsume() {
+		p.err = p.writer.WriteByte(']')
+	}
+}
+
 func (p *printer) skipRowsFor(nextPosit int) {
 	currentRow := p.index / p.digitsPerRow
 	nextRow := nextPosit / p.digitsPerRow
@@ -43,22 +91,29 @@ func (p *printer) skipRowsFor(nextPosit int) {
 	}
 }
 
+// stringWriter is a writer that can also write strings.
+type stringWriter interface {
+	io.Writer
+	io.StringWriter
+}
+
 type rowStarter interface {
-	Start(w *bufio.Writer, index int) error
+	Start(w stringWriter, index int) error
 	CountOn() bool
 }
 
 type countOnStarter struct {
 	zeroString    string
 	nonZeroString string
+	offset        int
 }
 
-func (c *countOnStarter) Start(w *bufio.Writer, index int) error {
+func (c *countOnStarter) Start(w stringWriter, index int) error {
 	if index == 0 {
 		_, err := w.WriteString(c.zeroString)
 		return err
 	}
-	_, err := fmt.Fprintf(w, c.nonZeroString, index)
+	_, err := fmt.Fprintf(w, c.nonZeroString, index-c.offset)
 	return err
 }
 
@@ -69,7 +124,7 @@ type countOffStarter struct {
 	nonZeroString string
 }
 
-func (c *countOffStarter) Start(w *bufio.Writer, index int) error {
+func (c *countOffStarter) Start(w stringWriter, index int) error {
 	if index == 0 {
 		_, err := w.WriteString(c.zeroString)
 		return err
@@ -80,52 +135,139 @@ func (c *countOffStarter) Start(w *bufio.Writer, index int) error {
 
 func (c *countOffStarter) CountOn() bool { return false }
 
+type rowNumberStarter struct {
+	digitsPerRow int
+	width        int
+	leadingZero  string
+}
+
+func (r *rowNumberStarter) Start(w stringWriter, index int) error {
+	_, err := fmt.Fprintf(w, "%*d  ", r.width, index/r.digitsPerRow+1)
+	if err != nil || r.leadingZero == "" {
+		return err
+	}
+	if index == 0 {
+		_, err = w.WriteString(r.leadingZero)
+	} else {
+		_, err = w.WriteString(strings.Repeat(" ", len(r.leadingZero)))
+	}
+	return err
+}
+
+func (r *rowNumberStarter) CountOn() bool { return true }
+
+type tabStarter struct {
+	digitsPerRow int
+	offset       int
+	rowNumbers   bool
+}
+
+func (t *tabStarter) Start(w stringWriter, index int) error {
+	value := index - t.offset
+	if t.rowNumbers {
+		value = index/t.digitsPerRow + 1
+	}
+	_, err := fmt.Fprintf(w, "%d\t", value)
+	return err
+}
+
+func (t *tabStarter) CountOn() bool { return true }
+
 type rawPrinter struct {
 	cWriter          *countingWriter
-	writer           *bufio.Writer
+	writer           *bufferedWriter
 	rowStarter       rowStarter
 	digitsPerRow     int
 	digitsPerColumn  int
+	columnSeparator  byte
 	trailingLineFeed bool
+	footer           string
+	headerLength     int
+	maxBytes         int
+	held             *bytes.Buffer
+	destination      io.Writer
+	lastBoundary     int
+	truncated        bool
 	index            int
 	indexInRow       int
 	err              error
 }
 
 func (p *rawPrinter) Init(
-	writer io.Writer, maxDigits int, settings *printerSettings) {
-	cWriter := &countingWriter{delegate: writer}
-	var bWriter *bufio.Writer
-	if settings.bufferSize <= 0 {
-		bWriter = bufio.NewWriter(cWriter)
-	} else {
-		bWriter = bufio.NewWriterSize(cWriter, settings.bufferSize)
+	writer io.Writer, start, maxDigits int, settings *printerSettings) {
+	var held *bytes.Buffer
+	destination := writer
+	if settings.maxBytes > 0 {
+		held = new(bytes.Buffer)
+		writer = held
 	}
+	cWriter := &countingWriter{delegate: writer}
+	bWriter := newBufferedWriter(cWriter, settings.bufferSize)
 	*p = rawPrinter{
 		cWriter:          cWriter,
 		writer:           bWriter,
-		rowStarter:       settings.computeRowStarter(maxDigits),
+		rowStarter:       settings.computeRowStarter(start, maxDigits),
 		digitsPerRow:     settings.digitsPerRow,
 		digitsPerColumn:  settings.digitsPerColumn,
+		columnSeparator:  ' ',
 		trailingLineFeed: settings.trailingLineFeed,
+		footer:           settings.footer,
+		maxBytes:         settings.maxBytes,
+		held:             held,
+		destination:      destination,
+	}
+	if settings.tabAlign {
+		p.columnSeparator = '\t'
+	}
+	if settings.header != "" {
+		p.headerLength, p.err = fmt.Fprintln(p.writer, settings.header)
+	}
+	if settings.ruler && settings.digitsPerRow > 0 && p.err == nil {
+		var n int
+		n, p.err = fmt.Fprintln(
+			p.writer, settings.rulerLine(p.rowStarter, start, maxDigits))
+		p.headerLength += n
 	}
 }
 
 func (p *rawPrinter) CanConsume() bool {
-	return p.err == nil
+	return p.err == nil && !p.truncated
 }
 
 func (p *rawPrinter) Consume(digit rune) {
+	p.consume(digit, "", "")
+}
+
+// ConsumeWithColor works like Consume except that it surrounds digit with
+// the ANSI escape sequence color and a reset. An empty color means no
+// escape sequences.
+func (p *rawPrinter) ConsumeWithColor(digit rune, color string) {
+	if color == "" {
+		p.consume(digit, "", "")
+		return
+	}
+	p.consume(digit, color, kColorReset)
+}
+
+// consume writes digit with before written just before it and after
+// written just after it. Any row or column separator comes before before.
+func (p *rawPrinter) consume(digit rune, before, after string) {
 	if !p.CanConsume() {
 		return
 	}
+	if p.held != nil && p.atBoundary() {
+		p.markBoundary()
+		if p.truncated {
+			return
+		}
+	}
 	if p.index == 0 {
 		p.err = p.rowStarter.Start(p.writer, 0)
 		if p.err != nil {
 			return
 		}
 	} else if p.digitsPerRow > 0 && p.index%p.digitsPerRow == 0 {
-		if p.BytesWritten()+p.bytesBuffered() > 0 {
+		if p.BytesWritten()+p.bytesBuffered() > p.headerLength {
 			_, p.err = fmt.Fprintln(p.writer)
 			if p.err != nil {
 				return
@@ -137,7 +279,13 @@ func (p *rawPrinter) Consume(digit rune) {
 		}
 		p.indexInRow = 0
 	} else if p.digitsPerColumn > 0 && p.indexInRow%p.digitsPerColumn == 0 {
-		p.err = p.writer.WriteByte(' ')
+		p.err = p.writer.WriteByte(p.columnSeparator)
+		if p.err != nil {
+			return
+		}
+	}
+	if before != "" {
+		_, p.err = p.writer.WriteString(before)
 		if p.err != nil {
 			return
 		}
@@ -146,18 +294,86 @@ func (p *rawPrinter) Consume(digit rune) {
 	if p.err != nil {
 		return
 	}
+	if after != "" {
+		_, p.err = p.writer.WriteString(after)
+		if p.err != nil {
+			return
+		}
+	}
 	p.index++
 	p.indexInRow++
 }
 
 func (p *rawPrinter) Finish() {
-	if p.err == nil && p.trailingLineFeed {
+	if p.held != nil && p.CanConsume() {
+		p.markBoundary()
+	}
+	if p.CanConsume() && p.footer != "" {
+		p.writeFooter()
+	}
+	if p.CanConsume() && p.trailingLineFeed {
 		_, p.err = fmt.Fprintln(p.writer)
 	}
 	err := p.writer.Flush()
 	if p.err == nil {
 		p.err = err
 	}
+	if p.held != nil && p.err == nil {
+		p.releaseHeld()
+	}
+}
+
+// atBoundary returns true if the next digit starts a new row or column
+// or if there are no rows or columns.
+func (p *rawPrinter) atBoundary() bool {
+	if p.digitsPerRow <= 0 && p.digitsPerColumn <= 0 {
+		return true
+	}
+	return p.index == 0 || p.atSeparator()
+}
+
+// markBoundary notes that everything printed so far may be kept when
+// output is limited to maxBytes. If what is printed so far is already too
+// big, markBoundary marks this instance as truncated instead.
+func (p *rawPrinter) markBoundary() {
+	size := p.BytesWritten() + p.bytesBuffered()
+	if size > p.maxBytes {
+		p.truncated = true
+		return
+	}
+	p.lastBoundary = size
+}
+
+// releaseHeld writes the held output to the destination writer keeping
+// only what fits within maxBytes.
+func (p *rawPrinter) releaseHeld() {
+	data := p.held.Bytes()
+	if p.truncated || len(data) > p.maxBytes {
+		data = data[:p.lastBoundary]
+	}
+	p.cWriter = &countingWriter{delegate: p.destination}
+	_, p.err = p.cWriter.Write(data)
+}
+
+// atSeparator returns true if the next digit starts a new row or column.
+func (p *rawPrinter) atSeparator() bool {
+	if p.index == 0 {
+		return false
+	}
+	if p.digitsPerRow > 0 && p.index%p.digitsPerRow == 0 {
+		return true
+	}
+	return p.digitsPerColumn > 0 && p.indexInRow%p.digitsPerColumn == 0
+}
+
+func (p *rawPrinter) writeFooter() {
+	if p.BytesWritten()+p.bytesBuffered() > p.headerLength {
+		_, p.err = fmt.Fprintln(p.writer)
+		if p.err != nil {
+			return
+		}
+	}
+	_, p.err = p.writer.WriteString(p.footer)
 }
 
 func (p *rawPrinter) BytesWritten() int {
@@ -184,9 +400,24 @@ type printerSettings struct {
 	bufferSize       int
 	trailingLineFeed bool
 	leadingDecimal   bool
+	decimalPoint     rune
+	header           string
+	footer           string
+	maxBytes         int
+	showValue        bool
+	skipEmptyRows    bool
+	padLastRow       bool
+	vertical         bool
+	rowNumbers       bool
+	tabAlign         bool
+	ruler            bool
+	highlight        Positions
+	highlightColor   string
+	bracketed        Positions
+	countOffset      int
 }
 
-func (p *printerSettings) digitCountWidth(maxDigits int) int {
+func (p *printerSettings) digitCountWidth(start, maxDigits int) int {
 	if !p.showCount || p.digitsPerRow <= 0 {
 		return 0
 	}
@@ -194,14 +425,139 @@ func (p *printerSettings) digitCountWidth(maxDigits int) int {
 		return 0
 	}
 	maxCounter := ((maxDigits - 1) / p.digitsPerRow) * p.digitsPerRow
-	return len(strconv.Itoa(maxCounter))
+	minCounter := (max(start, 0) / p.digitsPerRow) * p.digitsPerRow
+	return max(
+		len(strconv.Itoa(maxCounter-p.countOffset)),
+		len(strconv.Itoa(minCounter-p.countOffset)))
 }
 
-func (p *printerSettings) computeRowStarter(maxDigits int) rowStarter {
-	width := p.digitCountWidth(maxDigits)
+// digitsPerRowToFit returns the most digits per row such that each line
+// is at most width characters wide. It prefers a multiple of the digits
+// per column. It returns 1 if nothing fits.
+func (p *printerSettings) digitsPerRowToFit(start, maxDigits, width int) int {
+	bestAny, bestMultiple := 1, 0
+	for perRow := 1; perRow <= min(width, max(maxDigits, 1)); perRow++ {
+		if p.lineWidth(start, maxDigits, perRow) > width {
+			continue
+		}
+		bestAny = perRow
+		if p.digitsPerColumn > 0 && perRow%p.digitsPerColumn == 0 {
+			bestMultiple = perRow
+		}
+	}
+	if bestMultiple > 0 {
+		return bestMultiple
+	}
+	return bestAny
+}
+
+// lineWidth returns the width of the widest line when printing perRow
+// digits per row.
+func (p *printerSettings) lineWidth(start, maxDigits, perRow int) int {
+	settings := *p
+	settings.digitsPerRow = perRow
+	starter := settings.computeRowStarter(start, maxDigits)
+	var builder strings.Builder
+	writer := bufio.NewWriter(&builder)
+	margin := 0
+	for _, index := range []int{
+		0, (max(start, 0) / perRow) * perRow, ((maxDigits - 1) / perRow) * perRow} {
+		builder.Reset()
+		starter.Start(writer, max(index, 0))
+		writer.Flush()
+		margin = max(margin, utf8.RuneCountInString(builder.String()))
+	}
+	result := margin + perRow
+	if p.digitsPerColumn > 0 {
+		result += (perRow - 1) / p.digitsPerColumn
+	}
+	return result
+}
+
+// rulerLine returns the line of column indices that goes above the digits.
+// The left margin of the line is blank but as wide as the margin that
+// starter writes so that each index lines up with its digit. The line is
+// no wider than a row holding maxDigits digits.
+func (p *printerSettings) rulerLine(
+	starter rowStarter, start, maxDigits int) string {
+	var builder strings.Builder
+	writer := bufio.NewWriter(&builder)
+	starter.Start(writer, (max(start, 0)/p.digitsPerRow)*p.digitsPerRow)
+	writer.Flush()
+	margin := builder.String()
+	builder.Reset()
+	for _, r := range margin {
+		if r == '\t' {
+			builder.WriteByte('\t')
+		} else {
+			builder.WriteByte(' ')
+		}
+	}
+	separator := byte(' ')
+	if p.tabAlign {
+		separator = '\t'
+	}
+	for i := 0; i < min(p.digitsPerRow, maxDigits); i++ {
+		if i > 0 && p.digitsPerColumn > 0 && i%p.digitsPerColumn == 0 {
+			builder.WriteByte(separator)
+		}
+		builder.WriteByte(byte('0' + i%10))
+	}
+	return builder.String()
+}
+
+// withValueOf returns p with the value of s added to the header if p says
+// to show the value and s is a Number.
+func (p *printerSettings) withValueOf(s Sequence) *printerSettings {
+	n, ok := s.(Number)
+	if !p.showValue || !ok {
+		return p
+	}
+	result := *p
+	if result.header == "" {
+		result.header = n.String()
+	} else {
+		result.header += "\n" + n.String()
+	}
+	return &result
+}
+
+// leadingZero returns the "0." that goes before the first digit when
+// showing the leading decimal point.
+func (p *printerSettings) leadingZero() string {
+	if p.decimalPoint == 0 {
+		return "0."
+	}
+	return "0" + string(p.decimalPoint)
+}
+
+func (p *printerSettings) computeRowStarter(
+	start, maxDigits int) rowStarter {
+	if p.tabAlign {
+		if !p.showCount && !p.rowNumbers || p.digitsPerRow <= 0 {
+			return &countOffStarter{}
+		}
+		return &tabStarter{
+			digitsPerRow: p.digitsPerRow,
+			offset:       p.countOffset,
+			rowNumbers:   p.rowNumbers,
+		}
+	}
+	if p.rowNumbers && p.digitsPerRow > 0 {
+		result := &rowNumberStarter{
+			digitsPerRow: p.digitsPerRow,
+			width:        len(strconv.Itoa((maxDigits-1)/p.digitsPerRow + 1)),
+		}
+		if p.leadingDecimal {
+			result.leadingZero = p.leadingZero()
+		}
+		return result
+	}
+	width := p.digitCountWidth(start, maxDigits)
 	if width <= 0 {
 		if p.leadingDecimal {
-			return &countOffStarter{zeroString: "0.", nonZeroString: "  "}
+			return &countOffStarter{
+				zeroString: p.leadingZero(), nonZeroString: "  "}
 		} else if p.showCount {
 			return &countOffStarter{zeroString: "0  ", nonZeroString: "   "}
 		} else {
@@ -210,13 +566,15 @@ func (p *printerSettings) computeRowStarter(maxDigits int) rowStarter {
 	}
 	if p.leadingDecimal {
 		return &countOnStarter{
-			zeroString:    strings.Repeat(" ", width) + "0.",
+			zeroString:    strings.Repeat(" ", width) + p.leadingZero(),
 			nonZeroString: fmt.Sprintf("%%%dd  ", width),
+			offset:        p.countOffset,
 		}
 	}
 	return &countOnStarter{
-		zeroString:    strings.Repeat(" ", width-1) + "0  ",
+		zeroString:    fmt.Sprintf("%*d  ", width, -p.countOffset),
 		nonZeroString: fmt.Sprintf("%%%dd  ", width),
+		offset:        p.countOffset,
 	}
 }
 
@@ -293,6 +651,149 @@ func (f *formatter) addLeadingZeros(count int) {
 	}
 }
 
+func printSideBySide(
+	builder *strings.Builder,
+	a, b Sequence,
+	p Positions,
+	settings *printerSettings) {
+	rowSettings := *settings
+	rowSettings.showCount = false
+	rowSettings.skipEmptyRows = false
+	rowSettings.padLastRow = true
+	rowSettings.trailingLineFeed = false
+	rowSettings.header = ""
+	aRows := printRows(a, p, &rowSettings)
+	bRows := printRows(b, p, &rowSettings)
+	width := 0
+	for _, row := range aRows {
+		width = max(width, len(row))
+	}
+	rowStarter := settings.computeRowStarter(p.start(), p.End())
+	skipEmptyRows := settings.skipEmptyRows || rowStarter.CountOn()
+	writer := bufio.NewWriter(builder)
+	if settings.header != "" {
+		fmt.Fprintln(writer, settings.header)
+	}
+	empty := string(settings.missingDigit) + " "
+	rowsWritten := 0
+	for i := range max(len(aRows), len(bRows)) {
+		aRow, bRow := rowAt(aRows, i), rowAt(bRows, i)
+		if skipEmptyRows && strings.Trim(aRow+bRow, empty) == "" {
+			continue
+		}
+		if rowsWritten > 0 {
+			fmt.Fprintln(writer)
+		}
+		rowStarter.Start(writer, i*max(settings.digitsPerRow, 0))
+		line := fmt.Sprintf("%-*s | %s", width, aRow, bRow)
+		writer.WriteString(strings.TrimRight(line, " "))
+		rowsWritten++
+	}
+	if settings.trailingLineFeed {
+		fmt.Fprintln(writer)
+	}
+	writer.Flush()
+}
+
+func printRows(s Sequence, p Positions, settings *printerSettings) []string {
+	var builder strings.Builder
+	printer := newPrinter(&builder, p.start(), p.End(), settings)
+	fromSequenceWithPositions(s, p, printer)
+	printer.Finish()
+	if builder.Len() == 0 {
+		return nil
+	}
+	return strings.Split(builder.String(), "\n")
+}
+
+func rowAt(rows []string, index int) string {
+	if index >= len(rows) {
+		return ""
+	}
+	return rows[index]
+}
+
+// kBufferSize is the default size of the buffer that printing uses.
+const kBufferSize = 4096
+
+// bufferedWriter buffers what is written to cWriter. If the writer that
+// cWriter wraps is already a *bufio.Writer with a buffer at least as big as
+// kBufferSize, bufferedWriter writes to it directly instead of buffering
+// everything twice and counts the bytes it writes in cWriter itself. The
+// caller's *bufio.Writer then sees the same writes and flushes at the same
+// points as it would through a second buffer, so nothing observable
+// changes.
+type bufferedWriter struct {
+	*bufio.Writer
+	cWriter *countingWriter
+	direct  bool
+}
+
+// newBufferedWriter returns a bufferedWriter that writes to cWriter.
+// A positive size overrides the default buffer size and always gives the
+// returned bufferedWriter its own buffer.
+func newBufferedWriter(cWriter *countingWriter, size int) *bufferedWriter {
+	if size > 0 {
+		return &bufferedWriter{
+			Writer: bufio.NewWriterSize(cWriter, size), cWriter: cWriter}
+	}
+	if w, ok := cWriter.delegate.(*bufio.Writer); ok && w.Size() >= kBufferSize {
+		return &bufferedWriter{Writer: w, cWriter: cWriter, direct: true}
+	}
+	return &bufferedWriter{
+		Writer: bufio.NewWriterSize(cWriter, kBufferSize), cWriter: cWriter}
+}
+
+func (b *bufferedWriter) Write(p []byte) (n int, err error) {
+	n, err = b.Writer.Write(p)
+	b.count(n)
+	return
+}
+
+func (b *bufferedWriter) WriteByte(c byte) error {
+	err := b.Writer.WriteByte(c)
+	if err == nil {
+		b.count(1)
+	}
+	return err
+}
+
+func (b *bufferedWriter) WriteRune(r rune) (size int, err error) {
+	size, err = b.Writer.WriteRune(r)
+	b.count(size)
+	return
+}
+
+func (b *bufferedWriter) WriteString(s string) (n int, err error) {
+	n, err = b.Writer.WriteString(s)
+	b.count(n)
+	return
+}
+
+// Flush flushes this instance's own buffer. If this instance writes
+// directly to the caller's *bufio.Writer, Flush leaves it alone just as
+// flushing a second buffer would leave the caller's buffered bytes alone.
+func (b *bufferedWriter) Flush() error {
+	if b.direct {
+		return nil
+	}
+	return b.Writer.Flush()
+}
+
+// Buffered returns the number of bytes in this instance's own buffer.
+func (b *bufferedWriter) Buffered() int {
+	if b.direct {
+		return 0
+	}
+	return b.Writer.Buffered()
+}
+
+func (b *bufferedWriter) count(n int) {
+	if b.direct {
+		b.cWriter.bytesWritten += n
+	}
+}
+
 type countingWriter struct {
 	delegate     io.Writer
 	bytesWritten int
@@ -303,3 +804,12 @@ func (c *countingWriter) Write(p []byte) (n int, err error) {
 	c.bytesWritten += n
 	return
 }
+
+type appendWriter struct {
+	buffer []byte
+}
+
+func (a *appendWriter) Write(p []byte) (n int, err error) {
+	a.buffer = append(a.buffer, p...)
+	return len(p), nil
+}
@@ -0,0 +1,82 @@
+package sqroot
+
+import (
+	"iter"
+	"math/big"
+)
+
+const (
+	// The number of extra digits to use when approximating a Number with
+	// a rational value.
+	kGuardDigits = 10
+)
+
+// convergents returns the convergents of the continued fraction of x as
+// numerator, denominator pairs from first to last. The last convergent
+// equals x.
+func convergents(x *big.Rat) iter.Seq2[*big.Int, *big.Int] {
+	return func(yield func(num, denom *big.Int) bool) {
+		hPrev, h := big.NewInt(0), big.NewInt(1)
+		kPrev, k := big.NewInt(1), big.NewInt(0)
+		num := new(big.Int).Set(x.Num())
+		denom := new(big.Int).Set(x.Denom())
+		var term, remainder big.Int
+		for denom.Sign() != 0 {
+			term.DivMod(num, denom, &remainder)
+			hPrev, h = h, new(big.Int).Add(new(big.Int).Mul(&term, h), hPrev)
+			kPrev, k = k, new(big.Int).Add(new(big.Int).Mul(&term, k), kPrev)
+			if !yield(h, k) {
+				return
+			}
+			num.Set(denom)
+			denom.Set(&remainder)
+		}
+	}
+}
+
+// digitsForDenominator returns how many significant digits of n are needed
+// so that the convergents of n's truncated value with denominators no bigger
+// than maxDenominator match those of n.
+func digitsForDenominator(n Number, maxDenominator *big.Int) int {
+	return max(1, n.Exponent()+2*len(maxDenominator.String())+kGuardDigits)
+}
+
+// digitsForError returns how many significant digits of n are needed so
+// that truncating n to that many digits changes its value by much less than
+// maxErr. maxErr must be positive.
+func digitsForError(n Number, maxErr *big.Rat) int {
+	inverse := new(big.Int).Quo(maxErr.Denom(), maxErr.Num())
+	return max(1, n.Exponent()+len(inverse.String())+kGuardDigits)
+}
+
+// truncatedRat returns the exact value of n truncated to digits significant
+// digits.
+func truncatedRat(n Number, digits int) *big.Rat {
+	truncated := n.WithSignificant(digits)
+	var mantissa big.Int
+	count := 0
+	for digit := range truncated.Values() {
+		mantissa.Mul(&mantissa, ten).Add(&mantissa, big.NewInt(int64(digit)))
+		count++
+	}
+	result := new(big.Rat).SetInt(&mantissa)
+	return scaleByPowerOf10(result, n.Exponent()-count)
+}
+
+func scaleByPowerOf10(x *big.Rat, exp int) *big.Rat {
+	var power big.Int
+	if exp >= 0 {
+		power.Exp(ten, big.NewInt(int64(exp)), nil)
+		return x.Mul(x, new(big.Rat).SetInt(&power))
+	}
+	power.Exp(ten, big.NewInt(int64(-exp)), nil)
+	return x.Quo(x, new(big.Rat).SetInt(&power))
+}
+
+func ratPower(x *big.Rat, exp int) *big.Rat {
+	bigExp := big.NewInt(int64(exp))
+	var num, denom big.Int
+	num.Exp(x.Num(), bigExp, nil)
+	denom.Exp(x.Denom(), bigExp, nil)
+	return new(big.Rat).SetFrac(&num, &denom)
+}
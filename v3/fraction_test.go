@@ -0,0 +1,106 @@
+package sqroot
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAsFraction(t *testing.T) {
+	n := Sqrt(2)
+	assertFraction(t, 99, 70, n, 100)
+	assertFraction(t, 99, 70, n, 168)
+	assertFraction(t, 239, 169, n, 169)
+	assertFraction(t, 1, 1, n, 1)
+	assertFraction(t, 3, 2, n, 4)
+	assertFraction(t, 665857, 470832, n, 1000000)
+}
+
+func TestAsFractionSqrt3(t *testing.T) {
+	n := Sqrt(3)
+	assertFraction(t, 2, 1, n, 1)
+	assertFraction(t, 5, 3, n, 3)
+	assertFraction(t, 7, 4, n, 10)
+	assertFraction(t, 19, 11, n, 14)
+	assertFraction(t, 26, 15, n, 40)
+	assertFraction(t, 97, 56, n, 56)
+}
+
+func TestAsFractionExact(t *testing.T) {
+	n := NewNumberFromBigRat(big.NewRat(355, 113))
+	assertFraction(t, 355, 113, n, 1000000)
+	assertFraction(t, 22, 7, n, 100)
+	assertFraction(t, 1, 3, NewNumberFromBigRat(big.NewRat(1, 3)), 100)
+	fn, _ := NewFiniteNumber([]int{1, 2, 5}, 1)
+	assertFraction(t, 5, 4, fn, 1000)
+	assertFraction(t, 1, 1, fn, 3)
+	assertFraction(t, 5, 4, fn, 4)
+	assertFraction(t, 0, 1, zeroNumber, 1000)
+}
+
+func TestAsFractionSmall(t *testing.T) {
+	n := SqrtRat(2600, 1000000)
+	assertFraction(t, 0, 1, n, 18)
+	assertFraction(t, 1, 19, n, 19)
+	assertFraction(t, 1, 20, n, 38)
+	assertFraction(t, 5, 98, n, 98)
+}
+
+func TestAsFractionTooBig(t *testing.T) {
+	n := SqrtBigInt(new(big.Int).Exp(ten, big.NewInt(40), nil))
+	assertFraction(t, 0, 0, n, 1000)
+}
+
+func TestAsFractionPanics(t *testing.T) {
+	assert.Panics(t, func() { Sqrt(2).AsFraction(0) })
+}
+
+func TestRationalWithin(t *testing.T) {
+	n := Sqrt(2)
+	assertRationalWithin(t, 577, 408, n, big.NewRat(1, 100000))
+	assertRationalWithin(t, 99, 70, n, big.NewRat(1, 10000))
+	assertRationalWithin(t, 1, 1, n, big.NewRat(1, 2))
+	assertRationalWithin(t, 3, 2, n, big.NewRat(1, 10))
+	assertRationalWithin(t, 1, 1, n, big.NewRat(5, 1))
+	assertRationalWithin(
+		t, 1607521, 1136689, n, big.NewRat(1, 1000000000000))
+	assertRationalWithin(t, 97, 56, Sqrt(3), big.NewRat(1, 5000))
+}
+
+func TestRationalWithinExact(t *testing.T) {
+	n := NewNumberFromBigRat(big.NewRat(355, 113))
+	assertRationalWithin(t, 22, 7, n, big.NewRat(1, 100))
+	assertRationalWithin(t, 355, 113, n, big.NewRat(1, 10000000))
+	assertRationalWithin(t, 317, 1, Sqrt(100489), big.NewRat(1, 1000))
+	assertRationalWithin(t, 0, 1, zeroNumber, big.NewRat(1, 1000))
+	assertRationalWithin(t, 0, 1, SqrtRat(2600, 1000000), big.NewRat(1, 10))
+	assertRationalWithin(
+		t, 1, 20, SqrtRat(2600, 1000000), big.NewRat(1, 1000))
+}
+
+func TestRationalWithinErrors(t *testing.T) {
+	_, err := Sqrt(2).RationalWithin(new(big.Rat))
+	assert.Error(t, err)
+	_, err = Sqrt(2).RationalWithin(big.NewRat(-1, 10))
+	assert.Error(t, err)
+}
+
+func assertRationalWithin(
+	t *testing.T,
+	expectedNum, expectedDenom int64,
+	n Number,
+	maxErr *big.Rat) {
+	t.Helper()
+	actual, err := n.RationalWithin(maxErr)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewRat(expectedNum, expectedDenom), actual)
+}
+
+func assertFraction(
+	t *testing.T, expectedNum, expectedDenom int64, n Number, max int64) {
+	t.Helper()
+	num, denom := n.AsFraction(max)
+	assert.Equal(t, expectedNum, num)
+	assert.Equal(t, expectedDenom, denom)
+}
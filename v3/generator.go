@@ -2,6 +2,12 @@ package sqroot
 
 import (
 	"math/big"
+	"strings"
+)
+
+const (
+	// The number of decimal places to use when first computing an AGM.
+	kAGMInitialPrecision = 50
 )
 
 // Interface Generator lazily generates the digits of a Number.
@@ -20,6 +26,23 @@ type Generator interface {
 	Generate() (digits func() int, exp int)
 }
 
+// LimitGenerator returns a Generator that generates at most the first n
+// mantissa digits that g generates and the same exponent that g generates.
+// LimitGenerator lets callers build a Number with a finite number of
+// digits directly from a Generator that generates an infinite number of
+// digits. If n is zero or negative, the returned Generator generates zero.
+func LimitGenerator(g Generator, n int) Generator {
+	return &limitGenerator{delegate: g, limit: n}
+}
+
+// MapGenerator returns a Generator that generates f(d) for each mantissa
+// digit d that g generates and the same exponent that g generates. If f
+// returns a value outside of 0 and 9, the returned Generator regards that
+// as the end of the mantissa. f is not called once g has no more digits.
+func MapGenerator(g Generator, f func(digit int) int) Generator {
+	return &mapGenerator{delegate: g, f: f}
+}
+
 func newNRootGenerator(
 	num, denom *big.Int, newManager func() rootManager) Generator {
 	result := &nrootGenerator{newManager: newManager}
@@ -68,6 +91,47 @@ func (g *repeatingGenerator) Generate() (func() int, int) {
 	return gen, g.exp
 }
 
+type limitGenerator struct {
+	delegate Generator
+	limit    int
+}
+
+func (g *limitGenerator) Generate() (func() int, int) {
+	digits, exp := g.delegate.Generate()
+	count := 0
+	return func() int {
+		if count >= g.limit {
+			return -1
+		}
+		count++
+		return digits()
+	}, exp
+}
+
+type mapGenerator struct {
+	delegate Generator
+	f        func(digit int) int
+}
+
+func (g *mapGenerator) Generate() (func() int, int) {
+	digits, exp := g.delegate.Generate()
+	done := false
+	return func() int {
+		if done {
+			return -1
+		}
+		digit := digits()
+		if digit != -1 {
+			digit = g.f(digit)
+		}
+		if digitOutOfRange(digit) {
+			done = true
+			return -1
+		}
+		return digit
+	}, exp
+}
+
 type ratGenerator struct {
 	num   big.Int
 	denom big.Int
@@ -91,6 +155,91 @@ func (g *nrootGenerator) Generate() (func() int, int) {
 	return computeRootDigits(groups, manager), exp
 }
 
+type numberSqrtGenerator struct {
+	number Number
+}
+
+func (g *numberSqrtGenerator) Generate() (func() int, int) {
+	iterator := g.number.Iterator()
+	digits := func() int {
+		d, ok := iterator()
+		if !ok {
+			return -1
+		}
+		return d.Value
+	}
+	groups, exp := computeGroupsFromDigits(digits, g.number.Exponent())
+	return computeRootDigits(groups, newSqrtManager()), exp
+}
+
+type agmGenerator struct {
+	a          int64
+	b          int64
+	iterations int
+}
+
+func (g *agmGenerator) Generate() (func() int, int) {
+	precision := kAGMInitialPrecision
+	low, high := g.bounds(precision)
+	for low.Sign() == 0 || len(low.String()) != len(high.String()) {
+		precision *= 2
+		low, high = g.bounds(precision)
+	}
+	lowStr, highStr := low.String(), high.String()
+	exp := len(lowStr) - precision
+	index := 0
+	digits := func() int {
+		for {
+			if lowStr == highStr {
+				lowStr = strings.TrimRight(lowStr, "0")
+				highStr = lowStr
+				if index == len(lowStr) {
+					return -1
+				}
+			}
+			if index < len(lowStr) && lowStr[index] == highStr[index] {
+				result := int(lowStr[index] - '0')
+				index++
+				return result
+			}
+			precision *= 2
+			low, high := g.bounds(precision)
+			lowStr, highStr = low.String(), high.String()
+		}
+	}
+	return digits, exp
+}
+
+// bounds returns the lower and upper bounds of the AGM scaled by
+// 10^precision.
+func (g *agmGenerator) bounds(precision int) (low, high *big.Int) {
+	var scale big.Int
+	scale.Exp(ten, big.NewInt(int64(precision)), nil)
+	lowX := new(big.Int).Mul(big.NewInt(g.a), &scale)
+	lowY := new(big.Int).Mul(big.NewInt(g.b), &scale)
+	highX := new(big.Int).Set(lowX)
+	highY := new(big.Int).Set(lowY)
+	for range g.iterations {
+		var sum, product big.Int
+		sum.Add(lowX, lowY)
+		lowY = new(big.Int).Sqrt(product.Mul(lowX, lowY))
+		lowX = new(big.Int).Rsh(&sum, 1)
+		sum.Add(highX, highY).Add(&sum, one)
+		highY = ceilSqrt(product.Mul(highX, highY))
+		highX = new(big.Int).Rsh(&sum, 1)
+	}
+	return lowX, highX
+}
+
+func ceilSqrt(x *big.Int) *big.Int {
+	result := new(big.Int).Sqrt(x)
+	var square big.Int
+	if square.Mul(result, result).Cmp(x) < 0 {
+		result.Add(result, one)
+	}
+	return result
+}
+
 func digitOutOfRange(d int) bool {
 	return d < 0 || d > 9
 }
@@ -28,17 +28,25 @@ func zeroPattern(f func() (Digit, bool)) func() int {
 
 func kmp(f func() (Digit, bool), pattern []int, reverse bool) func() int {
 	kernel := newKmpKernel(pattern)
+
+	// recent holds the positions of the last len(pattern) digits so that
+	// forward matches report the position of their first digit even when
+	// there are gaps in the positions.
+	recent := make([]int, len(pattern))
+	count := 0
 	return func() int {
 		for {
 			d, ok := f()
 			if !ok {
 				return -1
 			}
+			recent[count%len(recent)] = d.Position
+			count++
 			if kernel.Visit(d.Value) {
 				if reverse {
 					return d.Position
 				}
-				return d.Position + 1 - len(pattern)
+				return recent[count%len(recent)]
 			}
 		}
 	}
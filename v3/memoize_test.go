@@ -55,3 +55,28 @@ func TestMemoizeAt(t *testing.T) {
 		assert.Equal(t, expected, actual[i])
 	}
 }
+
+func TestOnProgress(t *testing.T) {
+	progress := make(chan int, 100)
+	n := Sqrt(2, OnProgress(func(digitsComputed int) {
+		progress <- digitsComputed
+	}))
+	n.At(999)
+	for i := 1; i <= 10; i++ {
+		assert.Equal(t, 100*i, <-progress)
+	}
+	n.At(1450)
+	for i := 11; i <= 15; i++ {
+		assert.Equal(t, 100*i, <-progress)
+	}
+	assert.Empty(t, progress)
+}
+
+func TestOnProgressExact(t *testing.T) {
+	called := false
+	n := CubeRootRat(27, 8, OnProgress(func(digitsComputed int) {
+		called = true
+	}))
+	assert.Equal(t, "1.5", n.String())
+	assert.False(t, called)
+}
@@ -26,6 +26,7 @@ type numberSpec interface {
 	ScanValues(index, limit int, yield func(value int) bool)
 	At(index int) int
 	FirstN(n int) []int8
+	Known() (count int, complete bool)
 }
 
 type memoizer struct {
@@ -36,10 +37,21 @@ type memoizer struct {
 	data            []int8
 	maxLength       int
 	done            bool
+	onProgress      func(digitsComputed int)
 }
 
 func newMemoizeSpec(iter func() int) numberSpec {
-	result := &memoizer{iter: iter}
+	return newMemoizeSpecFrom(nil, iter, nil)
+}
+
+// newMemoizeSpecFrom returns a memoizer that already has data. iter
+// generates the digits that come after data. If onProgress is non-nil,
+// the memoizer calls it from its own goroutine with the total number of
+// digits each time it publishes more digits.
+func newMemoizeSpecFrom(
+	data []int8, iter func() int, onProgress func(int)) numberSpec {
+	result := &memoizer{
+		iter: iter, data: data, maxLength: len(data), onProgress: onProgress}
 	result.mustGrow = sync.NewCond(&result.mu)
 	result.updateAvailable = sync.NewCond(&result.mu)
 	go result.run()
@@ -68,6 +80,12 @@ func (m *memoizer) FirstN(n int) []int8 {
 	return data
 }
 
+func (m *memoizer) Known() (count int, complete bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.data), m.done
+}
+
 func (m *memoizer) IteratorAt(index, limit int) func() (Digit, bool) {
 	if index < 0 {
 		panic("index must be non-negative")
@@ -158,21 +176,28 @@ func (m *memoizer) setData(data []int8, done bool) {
 	m.updateAvailable.Broadcast()
 }
 
+func (m *memoizer) publish(data []int8, done bool) {
+	m.setData(data, done)
+	if m.onProgress != nil {
+		m.onProgress(len(data))
+	}
+}
+
 func (m *memoizer) run() {
-	var data []int8
+	data := m.data
 	for i := 0; i < kMaxChunks; i++ {
 		m.waitToGrow()
 		for j := 0; j < kMemoizerChunkSize; j++ {
 			x := m.iter()
 			if digitOutOfRange(x) {
-				m.setData(data, true)
+				m.publish(data, true)
 				return
 			}
 			data = append(data, int8(x))
 		}
-		m.setData(data, false)
+		m.publish(data, false)
 	}
-	m.setData(data, true)
+	m.publish(data, true)
 }
 
 type limitSpec struct {
@@ -226,3 +251,80 @@ func (l *limitSpec) FirstN(n int) []int8 {
 	}
 	return l.delegate.FirstN(n)
 }
+
+func (l *limitSpec) Known() (count int, complete bool) {
+	count, complete = l.delegate.Known()
+	if count >= l.limit {
+		return l.limit, true
+	}
+	return count, complete
+}
+
+// staticSpec is a numberSpec for digits that are all known up front. Unlike
+// memoizer, it needs no goroutine.
+type staticSpec struct {
+	data []int8
+}
+
+func newStaticSpec(digits func() int) numberSpec {
+	var data []int8
+	for x := digits(); !digitOutOfRange(x); x = digits() {
+		data = append(data, int8(x))
+	}
+	return &staticSpec{data: data}
+}
+
+func (s *staticSpec) At(index int) int {
+	if index < 0 || index >= len(s.data) {
+		return -1
+	}
+	return int(s.data[index])
+}
+
+func (s *staticSpec) FirstN(n int) []int8 {
+	if n <= 0 {
+		return nil
+	}
+	return s.data[:min(n, len(s.data))]
+}
+
+func (s *staticSpec) Known() (count int, complete bool) {
+	return len(s.data), true
+}
+
+func (s *staticSpec) IteratorAt(index, limit int) func() (Digit, bool) {
+	if index < 0 {
+		panic("index must be non-negative")
+	}
+	return func() (Digit, bool) {
+		if index >= min(limit, len(s.data)) {
+			return Digit{}, false
+		}
+		result := Digit{Position: index, Value: int(s.data[index])}
+		index++
+		return result, true
+	}
+}
+
+func (s *staticSpec) Scan(
+	index, limit int, yield func(index, value int) bool) {
+	if index < 0 {
+		panic("index must be non-negative")
+	}
+	for ; index < min(limit, len(s.data)); index++ {
+		if !yield(index, int(s.data[index])) {
+			return
+		}
+	}
+}
+
+func (s *staticSpec) ScanValues(index, limit int, yield func(value int) bool) {
+	if index < 0 {
+		panic("index must be non-negative")
+	}
+	for ; index < min(limit, len(s.data)); index++ {
+		if !yield(int(s.data[index])) {
+			return
+		}
+	}
+}
@@ -1,7 +1,9 @@
 package sqroot
 
 import (
+	"cmp"
 	"iter"
+	"slices"
 	"sort"
 )
 
@@ -45,26 +47,38 @@ func (p *PositionsBuilder) AddRange(start, end int) *PositionsBuilder {
 	return p
 }
 
+// Reset removes all the positions from this builder but keeps the memory
+// this builder has already allocated so that it can be reused without
+// allocating. Build never shares memory with a Positions instance that it
+// already returned, so Reset never changes such instances.
+func (p *PositionsBuilder) Reset() {
+	p.ranges = p.ranges[:0]
+	p.unsorted = false
+}
+
 // Build builds a Positions instance from this builder and resets this builder
-// so that it has no positions in it.
+// so that it has no positions in it. The returned Positions instance does
+// not share memory with this builder, so this builder keeps its memory for
+// building the next Positions instance.
 func (p *PositionsBuilder) Build() Positions {
+	defer p.Reset()
+	if len(p.ranges) == 0 {
+		return Positions{}
+	}
 	if !p.unsorted {
-		result := p.ranges
-		*p = PositionsBuilder{}
-		return Positions{ranges: result}
+		return Positions{ranges: slices.Clone(p.ranges)}
 	}
-	sort.Slice(
+	slices.SortFunc(
 		p.ranges,
-		func(i, j int) bool {
-			return p.ranges[i].Start < p.ranges[j].Start
+		func(a, b PositionRange) int {
+			return cmp.Compare(a.Start, b.Start)
 		},
 	)
-	var result []PositionRange
-	result = append(result, p.ranges[0])
+	result := make([]PositionRange, 1, len(p.ranges))
+	result[0] = p.ranges[0]
 	for _, prange := range p.ranges[1:] {
 		appendNotBefore(prange, &result)
 	}
-	*p = PositionsBuilder{}
 	return Positions{ranges: result}
 }
 
@@ -86,6 +100,19 @@ func Between(start, end int) Positions {
 	return pb.AddRange(start, end).Build()
 }
 
+// PositionsOf returns the positions of all the digits in s equal to digit.
+// Consecutive positions are coalesced into ranges. The returned Positions
+// can be passed to Fprint or Highlight to show every occurrence of digit.
+func PositionsOf(s FiniteSequence, digit int) Positions {
+	var pb PositionsBuilder
+	for index, value := range s.All() {
+		if value == digit {
+			pb.Add(index)
+		}
+	}
+	return pb.Build()
+}
+
 // Ranges returns a function that generates all the non overlapping ranges
 // of positions in p. The returned function generates all the ranges in
 // increasing order and returns false when there are no more.
@@ -113,6 +140,31 @@ func (p Positions) All() iter.Seq[PositionRange] {
 	}
 }
 
+// Lengths works like All except that it also yields the number of
+// positions in each range.
+func (p Positions) Lengths() iter.Seq2[PositionRange, int] {
+	return func(yield func(pr PositionRange, length int) bool) {
+		for _, pr := range p.ranges {
+			if !yield(pr, pr.End-pr.Start) {
+				return
+			}
+		}
+	}
+}
+
+// Filter returns a view of s that has only the digits of s whose positions
+// are in p. Unlike other FiniteSequences, the returned FiniteSequence can
+// have gaps in the middle. Its iterators yield only the selected digits
+// along with their zero based positions in s. Filter is like calling Fprint
+// with p except that it streams the selected digits instead of printing
+// them. Functions such as Matches that search for patterns in the returned
+// FiniteSequence ignore the gaps. That is, they treat the selected digits
+// as consecutive and report the position of the first digit of each match.
+// The returned FiniteSequence is cheap to create.
+func (p Positions) Filter(s Sequence) FiniteSequence {
+	return &filteredSequence{sequence: s, positions: p}
+}
+
 // End returns the last zero based position in p plus 1. If p is the zero
 // value, End returns 0.
 func (p Positions) End() int {
@@ -133,6 +185,131 @@ type PositionRange struct {
 	End int
 }
 
+func (p Positions) start() int {
+	if len(p.ranges) == 0 {
+		return 0
+	}
+	return p.ranges[0].Start
+}
+
+func (p Positions) contains(posit int) bool {
+	index := sort.Search(len(p.ranges), func(i int) bool {
+		return p.ranges[i].End > posit
+	})
+	return index < len(p.ranges) && p.ranges[index].Start <= posit
+}
+
+func (p Positions) between(start, end int) Positions {
+	var pb PositionsBuilder
+	for _, pr := range p.ranges {
+		pb.AddRange(max(start, pr.Start), min(end, pr.End))
+	}
+	return pb.Build()
+}
+
+type filteredSequence struct {
+	sequence  Sequence
+	positions Positions
+}
+
+func (f *filteredSequence) All() iter.Seq2[int, int] {
+	return func(yield func(index, value int) bool) {
+		for pr := range f.positions.All() {
+			for index, value := range f.rangeOf(pr).All() {
+				if !yield(index, value) {
+					return
+				}
+			}
+		}
+	}
+}
+
+func (f *filteredSequence) Values() iter.Seq[int] {
+	return func(yield func(value int) bool) {
+		for _, value := range f.All() {
+			if !yield(value) {
+				return
+			}
+		}
+	}
+}
+
+func (f *filteredSequence) Iterator() func() (Digit, bool) {
+	ranges := f.positions.Ranges()
+	current := func() (Digit, bool) { return Digit{}, false }
+	return func() (Digit, bool) {
+		for {
+			if d, ok := current(); ok {
+				return d, true
+			}
+			pr, ok := ranges()
+			if !ok {
+				return Digit{}, false
+			}
+			current = f.rangeOf(pr).Iterator()
+		}
+	}
+}
+
+func (f *filteredSequence) Backward() iter.Seq2[int, int] {
+	return func(yield func(index, value int) bool) {
+		for i := len(f.positions.ranges) - 1; i >= 0; i-- {
+			pr := f.positions.ranges[i]
+			for index, value := range f.rangeOf(pr).Backward() {
+				if !yield(index, value) {
+					return
+				}
+			}
+		}
+	}
+}
+
+func (f *filteredSequence) Reverse() func() (Digit, bool) {
+	i := len(f.positions.ranges)
+	current := func() (Digit, bool) { return Digit{}, false }
+	return func() (Digit, bool) {
+		for {
+			if d, ok := current(); ok {
+				return d, true
+			}
+			if i == 0 {
+				return Digit{}, false
+			}
+			i--
+			current = f.rangeOf(f.positions.ranges[i]).Reverse()
+		}
+	}
+}
+
+func (f *filteredSequence) WithStart(start int) Sequence {
+	return f.FiniteWithStart(start)
+}
+
+func (f *filteredSequence) FiniteWithStart(start int) FiniteSequence {
+	if start <= 0 {
+		return f
+	}
+	return f.withPositions(f.positions.between(start, f.positions.End()))
+}
+
+func (f *filteredSequence) WithEnd(end int) FiniteSequence {
+	if end >= f.positions.End() {
+		return f
+	}
+	return f.withPositions(f.positions.between(0, end))
+}
+
+func (f *filteredSequence) withPositions(p Positions) FiniteSequence {
+	return &filteredSequence{sequence: f.sequence, positions: p}
+}
+
+func (f *filteredSequence) rangeOf(pr PositionRange) FiniteSequence {
+	return f.sequence.WithStart(pr.Start).WithEnd(pr.End)
+}
+
+func (f *filteredSequence) private() {
+}
+
 func appendNotBefore(item PositionRange, ranges *[]PositionRange) {
 	length := len(*ranges)
 	lastItem := &(*ranges)[length-1]
@@ -1,7 +1,9 @@
 package sqroot
 
 import (
+	"errors"
 	"slices"
+	"strings"
 	"testing"
 
 	"github.com/keep94/consume2"
@@ -62,6 +64,93 @@ func TestPositionsBuilderSorted(t *testing.T) {
 	assert.Equal(t, 200, p.End())
 }
 
+func TestPositionsBuilderReset(t *testing.T) {
+	var pb PositionsBuilder
+	pb.AddRange(100, 110).AddRange(0, 10).Add(50)
+	pb.Reset()
+	assert.Zero(t, pb.Build())
+	pb.AddRange(100, 110).AddRange(0, 10)
+	pb.Reset()
+	pb.AddRange(20, 30)
+	p := pb.Build()
+	assert.Equal(t, []PositionRange{{Start: 20, End: 30}}, slices.Collect(p.All()))
+}
+
+func TestPositionsBuilderResetNoAlias(t *testing.T) {
+	var pb PositionsBuilder
+	pb.AddRange(0, 10).AddRange(20, 30)
+	first := pb.Build()
+	pb.AddRange(40, 50).AddRange(60, 70)
+	pb.Reset()
+	pb.AddRange(80, 90)
+	second := pb.Build()
+	pb.AddRange(100, 110)
+	pb.Reset()
+	pb.AddRange(120, 130)
+	assert.Equal(
+		t,
+		[]PositionRange{{Start: 0, End: 10}, {Start: 20, End: 30}},
+		slices.Collect(first.All()))
+	assert.Equal(
+		t, []PositionRange{{Start: 80, End: 90}}, slices.Collect(second.All()))
+}
+
+func TestPositionsBuilderResetKeepsCapacity(t *testing.T) {
+	var pb PositionsBuilder
+	for i := 0; i < 10; i++ {
+		pb.Add(2 * i)
+	}
+	pb.Reset()
+	allocs := testing.AllocsPerRun(100, func() {
+		for i := 0; i < 10; i++ {
+			pb.Add(2 * i)
+		}
+		pb.Reset()
+	})
+	assert.Zero(t, allocs)
+}
+
+func TestPositionsBuilderBuildKeepsCapacity(t *testing.T) {
+	var pb PositionsBuilder
+	for i := 0; i < 10; i++ {
+		pb.Add(2 * i)
+	}
+	pb.Build()
+	allocs := testing.AllocsPerRun(100, func() {
+		for i := 0; i < 10; i++ {
+			pb.Add(2 * i)
+		}
+		pb.Build()
+		pb.AddRange(100, 110).AddRange(0, 10)
+		pb.Reset()
+	})
+
+	// The only allocation is the slice in the returned Positions.
+	assert.Equal(t, 1.0, allocs)
+	allocs = testing.AllocsPerRun(100, func() {
+		for i := 9; i >= 0; i-- {
+			pb.Add(2 * i)
+		}
+		pb.Build()
+	})
+	assert.Equal(t, 1.0, allocs)
+}
+
+func TestPositionsBuilderBuildNoAlias(t *testing.T) {
+	var pb PositionsBuilder
+	first := pb.AddRange(0, 10).AddRange(20, 30).Build()
+	second := pb.AddRange(40, 50).AddRange(0, 5).Build()
+	pb.AddRange(60, 70).AddRange(80, 90)
+	assert.Equal(
+		t,
+		[]PositionRange{{Start: 0, End: 10}, {Start: 20, End: 30}},
+		slices.Collect(first.All()))
+	assert.Equal(
+		t,
+		[]PositionRange{{Start: 0, End: 5}, {Start: 40, End: 50}},
+		slices.Collect(second.All()))
+}
+
 func TestPositionsBuilderNegative(t *testing.T) {
 	var pb PositionsBuilder
 	pb.Add(-1)
@@ -84,3 +173,254 @@ func TestPositionsAllExitEarly(t *testing.T) {
 	}
 	assert.Equal(t, PositionRange{Start: 0, End: 10}, firstRange)
 }
+
+func TestPositionsLengths(t *testing.T) {
+	var pb PositionsBuilder
+	pb.AddRange(0, 10).Add(50).AddRange(100, 125).AddRange(120, 130)
+	p := pb.Build()
+	var ranges []PositionRange
+	var lengths []int
+	for pr, length := range p.Lengths() {
+		ranges = append(ranges, pr)
+		lengths = append(lengths, length)
+	}
+	assert.Equal(t, slices.Collect(p.All()), ranges)
+	assert.Equal(t, []int{10, 1, 30}, lengths)
+}
+
+func TestPositionsLengthsExitEarly(t *testing.T) {
+	var pb PositionsBuilder
+	pb.AddRange(0, 10).AddRange(100, 110)
+	count := 0
+	for range pb.Build().Lengths() {
+		count++
+		break
+	}
+	assert.Equal(t, 1, count)
+	var zero Positions
+	for range zero.Lengths() {
+		t.Error("Expected no ranges")
+	}
+}
+
+func TestPositionsFilter(t *testing.T) {
+	n := Sqrt(2)
+	var pb PositionsBuilder
+	p := pb.AddRange(3, 7).Add(10).AddRange(95, 105).AddRange(200, 203).Build()
+	s := p.Filter(n)
+	var expected []Digit
+	for pr := range p.All() {
+		for index, value := range n.WithStart(pr.Start).WithEnd(pr.End).All() {
+			expected = append(expected, Digit{Position: index, Value: value})
+		}
+	}
+	assert.Len(t, expected, 18)
+	var actual []Digit
+	for index, value := range s.All() {
+		actual = append(actual, Digit{Position: index, Value: value})
+	}
+	assert.Equal(t, expected, actual)
+	actual = nil
+	consume2.FromGenerator(s.Iterator(), consume2.AppendTo(&actual))
+	assert.Equal(t, expected, actual)
+	var values []int
+	for _, d := range expected {
+		values = append(values, d.Value)
+	}
+	assert.Equal(t, values, slices.Collect(s.Values()))
+	slices.Reverse(expected)
+	actual = nil
+	for index, value := range s.Backward() {
+		actual = append(actual, Digit{Position: index, Value: value})
+	}
+	assert.Equal(t, expected, actual)
+	actual = nil
+	consume2.FromGenerator(s.Reverse(), consume2.AppendTo(&actual))
+	assert.Equal(t, expected, actual)
+}
+
+func TestPositionsFilterSameAsPrint(t *testing.T) {
+	n := Sqrt(2)
+	var pb PositionsBuilder
+	p := pb.AddRange(110, 120).AddRange(200, 220).Add(301).Build()
+	assert.Equal(
+		t,
+		Sprint(n, p),
+		Swrite(p.Filter(n), LeadingDecimal(true), TrailingLF(false)))
+}
+
+func TestPositionsFilterWithStartAndEnd(t *testing.T) {
+	n := fakeNumber()
+	var pb PositionsBuilder
+	s := pb.AddRange(3, 7).AddRange(10, 15).AddRange(20, 23).Build().Filter(n)
+	assert.Same(t, s, s.WithStart(0))
+	assert.Same(t, s, s.WithEnd(23))
+	assert.Equal(t, "6712", DigitsToString(s.FiniteWithStart(5).WithEnd(12)))
+	assert.Equal(t, "34", DigitsToString(s.WithStart(12).WithEnd(14)))
+	assert.Equal(t, "123", DigitsToString(s.FiniteWithStart(16)))
+	assertEmpty(t, s.WithStart(15).WithEnd(20))
+	assertEmpty(t, s.FiniteWithStart(23))
+}
+
+func TestPositionsFilterShortSequence(t *testing.T) {
+	n := Sqrt(100489)
+	s := Between(1, 10).Filter(n)
+	assert.Equal(t, "17", DigitsToString(s))
+	assertEmpty(t, Between(3, 10).Filter(n))
+	var p Positions
+	assertEmpty(t, p.Filter(n))
+}
+
+func TestPositionsFilterExitEarly(t *testing.T) {
+	var pb PositionsBuilder
+	s := pb.AddRange(3, 7).AddRange(10, 15).Build().Filter(fakeNumber())
+	var position int
+	for index := range s.All() {
+		position = index
+		break
+	}
+	assert.Equal(t, 3, position)
+	for index := range s.Backward() {
+		position = index
+		break
+	}
+	assert.Equal(t, 14, position)
+}
+
+func TestPositionsOf(t *testing.T) {
+	n, _ := NewFiniteNumber([]int{7, 7, 1, 7, 0, 7, 7, 7, 2}, 0)
+	p := PositionsOf(n, 7)
+	assert.Equal(
+		t,
+		[]PositionRange{
+			{Start: 0, End: 2},
+			{Start: 3, End: 4},
+			{Start: 5, End: 8},
+		},
+		slices.Collect(p.All()))
+	assert.Equal(
+		t,
+		[]PositionRange{{Start: 4, End: 5}},
+		slices.Collect(PositionsOf(n, 0).All()))
+	assert.Equal(t, Positions{}, PositionsOf(n, 9))
+	assert.Equal(t, Positions{}, PositionsOf(n, 10))
+}
+
+func TestPositionsOfScan(t *testing.T) {
+	s := Sqrt(2).WithStart(100).WithEnd(1000)
+	var expected []int
+	for index, value := range s.All() {
+		if value == 7 {
+			expected = append(expected, index)
+		}
+	}
+	sevens := PositionsOf(s, 7).Filter(Sqrt(2))
+	assert.Equal(t, expected, PositionsBetween(sevens, 0, 1000))
+	assert.Equal(t, strings.Repeat("7", len(expected)), DigitsToString(sevens))
+}
+
+func TestRecords(t *testing.T) {
+	var pb PositionsBuilder
+	pb.AddRange(0, 3).AddRange(10, 13).Add(20)
+	s := pb.Build().Filter(Sqrt(2))
+	records := Records(s)
+	assert.Equal(
+		t,
+		[]Digit{
+			{Position: 0, Value: 1},
+			{Position: 1, Value: 4},
+			{Position: 2, Value: 1},
+			{Position: 10, Value: 3},
+			{Position: 11, Value: 7},
+			{Position: 12, Value: 3},
+			{Position: 20, Value: 0},
+		},
+		records)
+	for _, record := range records {
+		pb.Add(record.Position)
+	}
+	assert.Equal(t, records, Records(pb.Build().Filter(Sqrt(2))))
+	assert.Nil(t, Records(Sqrt(2).WithEnd(0)))
+}
+
+func TestInterleave(t *testing.T) {
+	a, _ := NewFiniteNumber([]int{3, 1, 7}, 3)
+	b, _ := NewFiniteNumber([]int{2, 5, 0, 9, 8}, 0)
+	assert.Equal(t, []int{3, 2, 1, 5, 7, 0, 9, 8}, Interleave(a, b))
+	assert.Equal(t, []int{2, 3, 5, 1, 0, 7, 9, 8}, Interleave(b, a))
+	assert.Equal(t, []int{3, 1, 7}, Interleave(a, zeroNumber))
+	assert.Equal(t, []int{3, 1, 7}, Interleave(zeroNumber, a))
+	assert.Nil(t, Interleave(zeroNumber, zeroNumber))
+	var pb PositionsBuilder
+	s := pb.AddRange(10, 12).Add(20).Build().Filter(fakeNumber())
+	assert.Equal(t, []int{1, 3, 2, 1, 1, 7}, Interleave(s, a))
+}
+
+func TestChunkTo(t *testing.T) {
+	var chunks [][]int
+	err := ChunkTo(Sqrt(2).WithEnd(25), 10, func(chunk []int) error {
+		chunks = append(chunks, slices.Clone(chunk))
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		[][]int{
+			{1, 4, 1, 4, 2, 1, 3, 5, 6, 2},
+			{3, 7, 3, 0, 9, 5, 0, 4, 8, 8},
+			{0, 1, 6, 8, 8},
+		},
+		chunks)
+	chunks = nil
+	err = ChunkTo(Sqrt(2).WithEnd(20), 10, func(chunk []int) error {
+		chunks = append(chunks, slices.Clone(chunk))
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Len(t, chunks, 2)
+	err = ChunkTo(zeroNumber, 10, func(chunk []int) error {
+		assert.Fail(t, "fn should not be called")
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Panics(t, func() {
+		ChunkTo(Sqrt(2).WithEnd(20), 0, func(chunk []int) error {
+			return nil
+		})
+	})
+}
+
+func TestChunkToError(t *testing.T) {
+	errSend := errors.New("send failed")
+	calls := 0
+	err := ChunkTo(Sqrt(2).WithEnd(1000), 10, func(chunk []int) error {
+		calls++
+		if calls == 3 {
+			return errSend
+		}
+		return nil
+	})
+	assert.ErrorIs(t, err, errSend)
+	assert.Equal(t, 3, calls)
+	err = ChunkTo(Sqrt(2).WithEnd(25), 10, func(chunk []int) error {
+		if len(chunk) < 10 {
+			return errSend
+		}
+		return nil
+	})
+	assert.ErrorIs(t, err, errSend)
+}
+
+func TestValuesBetween(t *testing.T) {
+	var pb PositionsBuilder
+	pb.AddRange(0, 3).AddRange(10, 13).Add(20)
+	s := pb.Build().Filter(fakeNumber())
+	assert.Equal(t, []int{3, 1, 2, 3}, ValuesBetween(s, 2, 20))
+	assert.Equal(t, []int{2, 10, 11, 12}, PositionsBetween(s, 2, 20))
+	assert.Equal(t, []int{2, 3, 1}, ValuesBetween(s, 11, 25))
+	assert.Equal(t, []int{11, 12, 20}, PositionsBetween(s, 11, 25))
+	assert.Empty(t, ValuesBetween(s, 3, 10))
+	assert.Empty(t, PositionsBetween(s, 3, 10))
+	assert.Equal(t, []int{5, 6, 7}, ValuesBetween(fakeNumber(), 4, 7))
+	assert.Equal(t, []int{4, 5, 6}, PositionsBetween(fakeNumber(), 4, 7))
+}
@@ -4,6 +4,7 @@ import (
 	"io"
 	"iter"
 	"os"
+	"slices"
 	"strings"
 
 	"github.com/keep94/consume2"
@@ -37,6 +38,38 @@ func ShowCount(on bool) Option {
 	})
 }
 
+// CountOffset subtracts offset from the digit count shown in the left
+// margin. For example, when printing a Sequence that starts at position
+// 500, CountOffset(500) shows the count relative to the start of the
+// Sequence rather than the start of the mantissa. CountOffset affects only
+// the digit count displayed, not which digits are printed.
+func CountOffset(offset int) Option {
+	return optionFunc(func(p *printerSettings) {
+		p.countOffset = offset
+	})
+}
+
+// RowNumbers shows the 1 based row number in the left margin instead of
+// the digit count if on is true. RowNumbers takes precedence over
+// ShowCount and CountOffset. RowNumbers has no effect when there are no
+// separate rows.
+func RowNumbers(on bool) Option {
+	return optionFunc(func(p *printerSettings) {
+		p.rowNumbers = on
+	})
+}
+
+// TabAlign separates columns with a tab instead of a space if on is true
+// so that the output lines up on tab stops. With TabAlign, the left margin
+// is the digit count or row number followed by a tab without any padding,
+// and there is no left margin unless ShowCount or RowNumbers is on.
+// TabAlign overrides LeadingDecimal.
+func TabAlign(on bool) Option {
+	return optionFunc(func(p *printerSettings) {
+		p.tabAlign = on
+	})
+}
+
 // MissingDigit sets the character to represent a missing digit.
 func MissingDigit(missingDigit rune) Option {
 	return optionFunc(func(p *printerSettings) {
@@ -58,6 +91,109 @@ func LeadingDecimal(on bool) Option {
 	})
 }
 
+// DecimalPoint sets the character to use for the decimal point that
+// LeadingDecimal prints. The default is period (.). For example,
+// DecimalPoint(',') prints "0," before the first digit.
+func DecimalPoint(decimalPoint rune) Option {
+	return optionFunc(func(p *printerSettings) {
+		p.decimalPoint = decimalPoint
+	})
+}
+
+// Ruler writes a line of column indices above the digits if on is true.
+// The indices go 0 through 9 and then repeat, and each one lines up with
+// the digits in its column so that it is easy to read off the position of
+// a digit within its row. The ruler goes after any header and counts
+// toward the number of bytes written. Ruler has no effect when there are
+// no separate rows.
+func Ruler(on bool) Option {
+	return optionFunc(func(p *printerSettings) {
+		p.ruler = on
+	})
+}
+
+// Header writes text followed by a line feed before the digits. The header
+// counts toward the number of bytes written. An empty text means no
+// header, which is the default.
+func Header(text string) Option {
+	return optionFunc(func(p *printerSettings) {
+		p.header = text
+	})
+}
+
+// Footer writes text on its own line after the digits. If TrailingLF is
+// on, the trailing line feed goes after the footer rather than before it.
+// The footer counts toward the number of bytes written. An empty text
+// means no footer, which is the default.
+func Footer(text string) Option {
+	return optionFunc(func(p *printerSettings) {
+		p.footer = text
+	})
+}
+
+// MaxBytes limits what is printed to at most n bytes. If everything fits
+// in n bytes, MaxBytes has no effect. Otherwise, printing stops cleanly
+// at the end of the last whole row or column that fits, and the footer
+// and trailing line feed are omitted. Stopping early in this way is not an
+// error, and the number of bytes written reflects what was actually
+// written. Zero or negative means no limit, which is the default.
+func MaxBytes(n int) Option {
+	return optionFunc(func(p *printerSettings) {
+		p.maxBytes = n
+	})
+}
+
+// ShowValue writes a line with the value of the Number being printed before
+// the digits if on is true. The value comes from the String method of the
+// Number. ShowValue has no effect when the Sequence being printed is not
+// a Number. If there is also a Header, the value goes after the header.
+func ShowValue(on bool) Option {
+	return optionFunc(func(p *printerSettings) {
+		p.showValue = on
+	})
+}
+
+// SkipEmptyRows omits rows that contain no digits if on is true. Rows
+// with no digits come from large gaps in the positions being printed.
+// When the digit count is shown in the left margin, rows with no digits
+// are always omitted since the count shows where each row starts.
+func SkipEmptyRows(on bool) Option {
+	return optionFunc(func(p *printerSettings) {
+		p.skipEmptyRows = on
+	})
+}
+
+// PadLastRow pads the last row with the missing digit character so that
+// it is as wide as the other rows if on is true. PadLastRow has no effect
+// when there are no separate rows.
+func PadLastRow(on bool) Option {
+	return optionFunc(func(p *printerSettings) {
+		p.padLastRow = on
+	})
+}
+
+// Vertical prints one digit per line with the position of each digit in
+// the left margin if on is true. Vertical overrides DigitsPerRow,
+// DigitsPerColumn, ShowCount, Ruler, and LeadingDecimal. CountOffset still
+// applies to the position shown.
+func Vertical(on bool) Option {
+	return optionFunc(func(p *printerSettings) {
+		p.vertical = on
+	})
+}
+
+// Highlight surrounds each digit whose position is in p with the ANSI
+// escape sequence colorCode and a reset sequence after so that terminals
+// show those digits in color. For example, colorCode "\x1b[31m" shows the
+// digits in red. Digits not in p are printed as usual. The escape
+// sequences count toward the number of bytes written.
+func Highlight(p Positions, colorCode string) Option {
+	return optionFunc(func(s *printerSettings) {
+		s.highlight = p
+		s.highlightColor = colorCode
+	})
+}
+
 func bufferSize(size int) Option {
 	return optionFunc(func(p *printerSettings) {
 		p.bufferSize = size
@@ -67,7 +203,8 @@ func bufferSize(size int) Option {
 // Sequence represents a sequence of digits of either finite or infinite
 // length within the mantissa of a real number. Although they can start
 // and optionally end anywhere within a mantissa, Sequences must be
-// contiguous. That is they can have no gaps in the middle.
+// contiguous. That is they can have no gaps in the middle. The one
+// exception is the FiniteSequence that Positions.Filter returns.
 type Sequence interface {
 
 	// All returns the 0 based position and value of each digit in this
@@ -113,6 +250,33 @@ type FiniteSequence interface {
 	FiniteWithStart(start int) FiniteSequence
 }
 
+// FwriteTokens writes all the digits of s to w with each digit written as
+// tokens[digit]. FwriteTokens writes sep between tokens and does no other
+// formatting. FwriteTokens returns the number of bytes written and any
+// error encountered.
+func FwriteTokens(
+	w io.Writer, s FiniteSequence, tokens [10]string, sep string) (
+	written int, err error) {
+	cWriter := &countingWriter{delegate: w}
+	writer := newBufferedWriter(cWriter, 0)
+	first := true
+	for digit := range s.Values() {
+		if !first {
+			if _, err = writer.WriteString(sep); err != nil {
+				break
+			}
+		}
+		if _, err = writer.WriteString(tokens[digit]); err != nil {
+			break
+		}
+		first = false
+	}
+	if ferr := writer.Flush(); err == nil {
+		err = ferr
+	}
+	return cWriter.bytesWritten, err
+}
+
 // Fprint prints digits of s to w. Unless using advanced functionality,
 // prefer Fwrite, Write, and Swrite to Fprint, Print, and Sprint.
 // Fprint returns the number of bytes written and any error encountered.
@@ -122,19 +286,75 @@ type FiniteSequence interface {
 // line feed, and show the leading decimal point.
 func Fprint(w io.Writer, s Sequence, p Positions, options ...Option) (
 	written int, err error) {
-	settings := &printerSettings{
-		digitsPerRow:    50,
-		digitsPerColumn: 5,
-		showCount:       true,
-		missingDigit:    '.',
-		leadingDecimal:  true,
-	}
-	printer := newPrinter(w, p.End(), mutateSettings(options, settings))
+	settings := mutateSettings(options, newFprintSettings())
+	printer := newPrinter(w, p.start(), p.End(), settings.withValueOf(s))
 	fromSequenceWithPositions(s, p, printer)
 	printer.Finish()
 	return printer.BytesWritten(), printer.Err()
 }
 
+// FprintFit works like Fprint except that it chooses the number of digits
+// per row so that each line is at most width characters wide including the
+// left margin and the spaces between columns. FprintFit prefers a number of
+// digits per row that is a multiple of the digits per column. If even one
+// digit per row does not fit, FprintFit prints one digit per row.
+// FprintFit ignores any DigitsPerRow option.
+func FprintFit(
+	w io.Writer, s Sequence, p Positions, width int, options ...Option) (
+	written int, err error) {
+	settings := mutateSettings(options, newFprintSettings())
+	perRow := settings.digitsPerRowToFit(p.start(), p.End(), width)
+	options = append(slices.Clone(options), DigitsPerRow(perRow))
+	return Fprint(w, s, p, options...)
+}
+
+// FprintSideBySide works like Fprint except that it prints the digits of a
+// and b next to each other so that digits at the same position line up.
+// Each line shows a row of digits from a, a vertical bar, and the same row
+// of digits from b. FprintSideBySide ignores the LeadingDecimal option.
+func FprintSideBySide(
+	w io.Writer, a, b Sequence, p Positions, options ...Option) (
+	written int, err error) {
+	options = append(slices.Clone(options), LeadingDecimal(false))
+	settings := mutateSettings(options, newFprintSettings())
+	var builder strings.Builder
+	printSideBySide(&builder, a, b, p, settings)
+	return io.WriteString(w, builder.String())
+}
+
+// FprintMatches prints the digits of s surrounding each match of pattern
+// to w. Each match is shown along with up to before digits before it and
+// up to after digits after it. The matching digits appear in square
+// brackets. Matches that overlap or touch share the same brackets, and
+// brackets never span a row or column break. FprintMatches accepts the
+// same options as Fprint and has the same defaults.
+func FprintMatches(
+	w io.Writer,
+	s FiniteSequence,
+	pattern []int,
+	before, after int,
+	options ...Option) (written int, err error) {
+	var windows, matched PositionsBuilder
+	for index := range matches(s, pattern) {
+		windows.AddRange(index-before, index+len(pattern)+after)
+		matched.AddRange(index, index+len(pattern))
+	}
+	bracketed := matched.Build()
+	options = append(slices.Clone(options), optionFunc(
+		func(p *printerSettings) {
+			p.bracketed = bracketed
+		}))
+	return Fprint(w, s, windows.Build(), options...)
+}
+
+// FprintN works like Fprint except that it prints the first n digits of s.
+// FprintN(w, s, n, options...) is the same as
+// Fprint(w, s, UpTo(n), options...).
+func FprintN(w io.Writer, s Sequence, n int, options ...Option) (
+	written int, err error) {
+	return Fprint(w, s, UpTo(n), options...)
+}
+
 // Fwrite writes all the digits of s to w. Fwrite returns the number of bytes
 // written and any error encountered. For options, the default is 50 digits
 // per row, 5 digits per column, show digit count, period (.) for missing
@@ -149,12 +369,61 @@ func Fwrite(w io.Writer, s FiniteSequence, options ...Option) (
 		missingDigit:     '.',
 		trailingLineFeed: true,
 	}
-	printer := newPrinter(w, endOf(s), mutateSettings(options, settings))
+	settings = mutateSettings(options, settings)
+	printer := newPrinter(w, startOf(s), endOf(s), settings.withValueOf(s))
 	consume2.FromGenerator[Digit](s.Iterator(), printer)
 	printer.Finish()
 	return printer.BytesWritten(), printer.Err()
 }
 
+// FwriteNumber works like Fwrite except that it writes the first
+// sigDigits significant digits of n and shows the value of those digits
+// before them. If n has fewer than sigDigits significant digits,
+// FwriteNumber writes all of them. Passing ShowValue(false) in options
+// turns off showing the value. FwriteNumber panics if sigDigits is
+// negative.
+func FwriteNumber(w io.Writer, n Number, sigDigits int, options ...Option) (
+	written int, err error) {
+	options = append([]Option{ShowValue(true)}, options...)
+	return Fwrite(w, n.WithSignificant(sigDigits), options...)
+}
+
+// FwriteCSV writes all the digits of s to w as comma separated values.
+// Each digit is its own field, and each line has perRow fields except
+// possibly the last. Zero or negative perRow means all the digits go on one
+// line. Each line, including the last, ends with a line feed. FwriteCSV
+// returns the number of bytes written and any error encountered.
+func FwriteCSV(w io.Writer, s FiniteSequence, perRow int) (
+	written int, err error) {
+	cWriter := &countingWriter{delegate: w}
+	writer := newBufferedWriter(cWriter, 0)
+	indexInRow := 0
+	for digit := range s.Values() {
+		if indexInRow > 0 {
+			if err = writer.WriteByte(','); err != nil {
+				break
+			}
+		}
+		if err = writer.WriteByte('0' + byte(digit)); err != nil {
+			break
+		}
+		indexInRow++
+		if indexInRow == perRow {
+			if err = writer.WriteByte('\n'); err != nil {
+				break
+			}
+			indexInRow = 0
+		}
+	}
+	if err == nil && indexInRow > 0 {
+		err = writer.WriteByte('\n')
+	}
+	if ferr := writer.Flush(); err == nil {
+		err = ferr
+	}
+	return cWriter.bytesWritten, err
+}
+
 // Sprint works like Fprint and prints digits of s to a string.
 func Sprint(s Sequence, p Positions, options ...Option) string {
 	var builder strings.Builder
@@ -162,6 +431,12 @@ func Sprint(s Sequence, p Positions, options ...Option) string {
 	return builder.String()
 }
 
+// SprintN works like FprintN and prints the first n digits of s to a
+// string.
+func SprintN(s Sequence, n int, options ...Option) string {
+	return Sprint(s, UpTo(n), options...)
+}
+
 // Swrite works like Fwrite and writes all the digits of s to returned string.
 func Swrite(s FiniteSequence, options ...Option) string {
 	var builder strings.Builder
@@ -175,6 +450,19 @@ func Print(s Sequence, p Positions, options ...Option) (
 	return Fprint(os.Stdout, s, p, options...)
 }
 
+// PrintMatches works like FprintMatches and prints to stdout.
+func PrintMatches(
+	s FiniteSequence, pattern []int, before, after int, options ...Option) (
+	written int, err error) {
+	return FprintMatches(os.Stdout, s, pattern, before, after, options...)
+}
+
+// PrintN works like FprintN and prints the first n digits of s to stdout.
+func PrintN(s Sequence, n int, options ...Option) (
+	written int, err error) {
+	return Print(s, UpTo(n), options...)
+}
+
 // Write works like Fwrite and writes all the digits of s to stdout.
 func Write(s FiniteSequence, options ...Option) (
 	written int, err error) {
@@ -190,6 +478,116 @@ func DigitsToString(s FiniteSequence) string {
 	return sb.String()
 }
 
+// ValuesBetween returns the values of the digits in s that have zero based
+// positions between start inclusive and end exclusive. If s has gaps, such
+// as the FiniteSequence that Positions.Filter returns, ValuesBetween omits
+// the missing positions. PositionsBetween returns the corresponding
+// positions.
+func ValuesBetween(s Sequence, start, end int) []int {
+	return slices.Collect(s.WithStart(start).WithEnd(end).Values())
+}
+
+// PositionsBetween returns the zero based positions of the digits in s that
+// are between start inclusive and end exclusive. The returned positions
+// line up with the values that ValuesBetween returns.
+func PositionsBetween(s Sequence, start, end int) []int {
+	var result []int
+	for index := range s.WithStart(start).WithEnd(end).All() {
+		result = append(result, index)
+	}
+	return result
+}
+
+// Records returns the position and value of each digit in s as a slice of
+// Digit so that callers can inspect or serialize the digits without
+// iterating. If s is empty, Records returns nil.
+func Records(s FiniteSequence) []Digit {
+	var result []Digit
+	for index, value := range s.All() {
+		result = append(result, Digit{Position: index, Value: value})
+	}
+	return result
+}
+
+// Interleave returns the digit values of a and b alternating between the
+// two starting with a, that is a[0], b[0], a[1], b[1], and so on where a[i]
+// is the ith digit of a regardless of its position. Once the shorter of a
+// and b runs out, Interleave appends the rest of the digits of the longer
+// one. If both a and b are empty, Interleave returns nil.
+func Interleave(a, b FiniteSequence) []int {
+	var result []int
+	bNext, bStop := iter.Pull(b.Values())
+	defer bStop()
+	for aValue := range a.Values() {
+		result = append(result, aValue)
+		if bValue, ok := bNext(); ok {
+			result = append(result, bValue)
+		}
+	}
+	for bValue, ok := bNext(); ok; bValue, ok = bNext() {
+		result = append(result, bValue)
+	}
+	return result
+}
+
+// ChunkTo calls fn with successive chunks of the digit values of s from
+// beginning to end. Each chunk has size digits except that the last chunk
+// may have fewer. The slice passed to fn is reused between calls, so fn
+// must copy it to keep it past the call. If fn returns an error, ChunkTo
+// stops and returns that error. If s is empty, ChunkTo never calls fn.
+// ChunkTo panics if size is not positive.
+func ChunkTo(s FiniteSequence, size int, fn func(chunk []int) error) error {
+	if size <= 0 {
+		panic("size must be positive")
+	}
+	chunk := make([]int, 0, size)
+	for value := range s.Values() {
+		chunk = append(chunk, value)
+		if len(chunk) == size {
+			if err := fn(chunk); err != nil {
+				return err
+			}
+			chunk = chunk[:0]
+		}
+	}
+	if len(chunk) > 0 {
+		return fn(chunk)
+	}
+	return nil
+}
+
+// ForEach calls fn with the zero based position and value of each digit in
+// s that has a position less than limit. ForEach visits the digits from
+// beginning to end and stops early if fn returns false.
+func ForEach(s Sequence, limit int, fn func(posit, digit int) bool) {
+	for index, value := range s.WithEnd(limit).All() {
+		if !fn(index, value) {
+			return
+		}
+	}
+}
+
+// ForEachReverse calls fn with the zero based position and value of each
+// digit in s. ForEachReverse visits the digits from end to beginning and
+// stops early if fn returns false.
+func ForEachReverse(s FiniteSequence, fn func(posit, digit int) bool) {
+	for index, value := range s.Backward() {
+		if !fn(index, value) {
+			return
+		}
+	}
+}
+
+func newFprintSettings() *printerSettings {
+	return &printerSettings{
+		digitsPerRow:    50,
+		digitsPerColumn: 5,
+		showCount:       true,
+		missingDigit:    '.',
+		leadingDecimal:  true,
+	}
+}
+
 func endOf(s FiniteSequence) int {
 	for index := range s.Backward() {
 		return index + 1
@@ -197,6 +595,13 @@ func endOf(s FiniteSequence) int {
 	return 0
 }
 
+func startOf(s Sequence) int {
+	for index := range s.All() {
+		return index
+	}
+	return 0
+}
+
 func fromSequenceWithPositions(
 	s Sequence, p Positions, consumer consume2.Consumer[Digit]) {
 	for pr := range p.All() {
@@ -216,6 +621,13 @@ func mutateSettings(
 	for _, option := range options {
 		option.mutate(settings)
 	}
+	if settings.vertical {
+		settings.digitsPerRow = 1
+		settings.digitsPerColumn = 0
+		settings.showCount = true
+		settings.ruler = false
+		settings.leadingDecimal = false
+	}
 	return settings
 }
 
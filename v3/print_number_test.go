@@ -2,6 +2,8 @@ package sqroot
 
 import (
 	"errors"
+	"math"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -25,6 +27,22 @@ func TestPrintNoOptions(t *testing.T) {
 	assert.Equal(t, expected, actual)
 }
 
+func TestPrintN(t *testing.T) {
+	n := Sqrt(2)
+	assert.Equal(t, Sprint(n, UpTo(112)), SprintN(n, 112))
+	assert.Equal(
+		t,
+		Sprint(n, UpTo(37), DigitsPerRow(10), ShowCount(false)),
+		SprintN(n, 37, DigitsPerRow(10), ShowCount(false)))
+	assert.Equal(t, "", SprintN(n, 0))
+	assert.Equal(t, "", SprintN(n, -1))
+	var builder strings.Builder
+	written, err := FprintN(&builder, n, 112)
+	assert.NoError(t, err)
+	assert.Equal(t, Sprint(n, UpTo(112)), builder.String())
+	assert.Equal(t, builder.Len(), written)
+}
+
 func TestPrintLessThanOneRow(t *testing.T) {
 	actual := Sprint(
 		fakeNumber(), UpTo(12), DigitsPerRow(12), DigitsPerColumn(0))
@@ -234,6 +252,63 @@ func TestPrinterWithPositions2(t *testing.T) {
 	assert.Equal(t, expected, actual)
 }
 
+func TestPrinterSkipEmptyRows(t *testing.T) {
+	var pb PositionsBuilder
+	p := pb.AddRange(3, 6).AddRange(1000, 1005).Add(1207).Build()
+	actual := Sprint(
+		Sqrt(2),
+		p,
+		DigitsPerRow(20),
+		ShowCount(false),
+		SkipEmptyRows(true))
+	expected := `0....42 1.... ..... .....
+  20896 ..... ..... .....
+  ..... ..5`
+	assert.Equal(t, expected, actual)
+	actual = Sprint(Sqrt(2), p, DigitsPerRow(20), ShowCount(false))
+	assert.Equal(t, 60, strings.Count(actual, "\n"))
+}
+
+func TestPrinterSkipEmptyRowsShowCount(t *testing.T) {
+	var pb PositionsBuilder
+	p := pb.AddRange(3, 6).AddRange(1000, 1005).Add(1207).Build()
+	expected := `    0....42 1.... ..... .....
+1000  20896 ..... ..... .....
+1200  ..... ..5`
+	assert.Equal(t, expected, Sprint(Sqrt(2), p, DigitsPerRow(20)))
+	assert.Equal(
+		t,
+		expected,
+		Sprint(Sqrt(2), p, DigitsPerRow(20), SkipEmptyRows(true)))
+}
+
+func TestWriterSkipEmptyRows(t *testing.T) {
+	var pb PositionsBuilder
+	p := pb.AddRange(20, 25).AddRange(100, 103).Build()
+	actual := Swrite(
+		p.Filter(fakeNumber()),
+		DigitsPerRow(10),
+		ShowCount(false),
+		SkipEmptyRows(true))
+	expected := `12345 .....
+123
+`
+	assert.Equal(t, expected, actual)
+}
+
+func TestPrinterCountOffset(t *testing.T) {
+	actual := Sprint(
+		Sqrt(2), Between(500, 520), DigitsPerRow(10), CountOffset(500))
+	expected := ` 0  35288 50926
+10  48612 49497`
+	assert.Equal(t, expected, actual)
+	actual = Sprint(Sqrt(2), UpTo(30), DigitsPerRow(10), CountOffset(10))
+	expected = `   0.14142 13562
+  0  37309 50488
+ 10  01688 72420`
+	assert.Equal(t, expected, actual)
+}
+
 func TestPrinterWithStart(t *testing.T) {
 	number := fakeNumber()
 	actual := Sprint(number.WithStart(502), UpTo(505))
@@ -325,6 +400,335 @@ func TestDigitsToString(t *testing.T) {
 	assert.Empty(t, DigitsToString(n.WithStart(4).WithEnd(3)))
 }
 
+func TestPrinterPadLastRow(t *testing.T) {
+	n := fakeNumber()
+	actual := Sprint(
+		n,
+		UpTo(13),
+		DigitsPerRow(10),
+		DigitsPerColumn(0),
+		ShowCount(false),
+		MissingDigit('-'),
+		PadLastRow(true))
+	expected := `0.1234567890
+  123-------`
+	assert.Equal(t, expected, actual)
+}
+
+func TestPrinterHeader(t *testing.T) {
+	n := fakeNumber()
+	var sb strings.Builder
+	written, err := Fprint(
+		&sb, n, UpTo(15), Header("n"), DigitsPerRow(10), DigitsPerColumn(0))
+	assert.NoError(t, err)
+	expected := `n
+  0.1234567890
+10  12345`
+	assert.Equal(t, expected, sb.String())
+	assert.Equal(t, len(expected), written)
+}
+
+func TestPrinterRowNumbers(t *testing.T) {
+	actual := Sprint(
+		Sqrt(2), Between(5, 25), DigitsPerRow(10), RowNumbers(true))
+	expected := `1  0...... 13562
+2    37309 50488
+3    01688`
+	assert.Equal(t, expected, actual)
+}
+
+func TestPrintFit(t *testing.T) {
+	n := Sqrt(2)
+	for _, width := range []int{1, 8, 20, 41, 60, 80, 132} {
+		var sb strings.Builder
+		_, err := FprintFit(&sb, n, UpTo(1000), width)
+		assert.NoError(t, err)
+		for _, line := range strings.Split(sb.String(), "\n") {
+			assert.LessOrEqual(t, len(line), max(width, 7))
+		}
+	}
+}
+
+func TestPrintFitDigitsPerRow(t *testing.T) {
+	var sb strings.Builder
+	FprintFit(&sb, fakeNumber(), UpTo(1000), 80)
+	lines := strings.Split(sb.String(), "\n")
+	expected := `   0.12345 67890 12345 67890 12345 67890 12345 67890 12345 67890 12345 67890
+ 60  12345 67890 12345 67890 12345 67890 12345 67890 12345 67890 12345 67890`
+	assert.Equal(t, expected, strings.Join(lines[:2], "\n"))
+	sb.Reset()
+	FprintFit(
+		&sb,
+		fakeNumber(),
+		UpTo(20),
+		11,
+		DigitsPerColumn(0),
+		ShowCount(false))
+	assert.Equal(t, "0.123456789\n  012345678\n  90", sb.String())
+}
+
+func TestPrinterTabAlign(t *testing.T) {
+	actual := Sprint(
+		Sqrt(2), Between(3, 17), DigitsPerRow(10), TabAlign(true))
+	expected := "0\t...42\t13562\n10\t37309\t50"
+	assert.Equal(t, expected, actual)
+}
+
+func TestPrinterDecimalPoint(t *testing.T) {
+	actual := Sprint(
+		Sqrt(2), UpTo(15), DigitsPerRow(10), DecimalPoint(','))
+	expected := `  0,14142 13562
+10  37309`
+	assert.Equal(t, expected, actual)
+	actual = Sprint(
+		Sqrt(2), UpTo(10), DigitsPerColumn(0), DecimalPoint(','))
+	assert.Equal(t, "0,1414213562", actual)
+}
+
+func TestPrinterRuler(t *testing.T) {
+	actual := Sprint(Sqrt(2), UpTo(30), DigitsPerRow(12), Ruler(true))
+	expected := `    01234 56789 01
+  0.14142 13562 37
+12  30950 48801 68
+24  87242 0`
+	assert.Equal(t, expected, actual)
+}
+
+func TestPrinterRulerHeader(t *testing.T) {
+	actual := Sprint(
+		Sqrt(2),
+		Between(15, 40),
+		DigitsPerRow(12),
+		Ruler(true),
+		Header("Hello"))
+	expected := `Hello
+    01234 56789 01
+12  ...50 48801 68
+24  87242 09698 07
+36  8569`
+	assert.Equal(t, expected, actual)
+}
+
+func TestPrinterRulerOtherMargins(t *testing.T) {
+	actual := Sprint(
+		Sqrt(2), UpTo(20), DigitsPerRow(10), Ruler(true), RowNumbers(true))
+	expected := `     01234 56789
+1  0.14142 13562
+2    37309 50488`
+	assert.Equal(t, expected, actual)
+	actual = Sprint(
+		Sqrt(2), UpTo(20), DigitsPerRow(10), Ruler(true), TabAlign(true))
+	expected = " \t01234\t56789\n0\t14142\t13562\n10\t37309\t50488"
+	assert.Equal(t, expected, actual)
+	assert.Equal(t, "  01234 567\n0.14142 135", Sprint(Sqrt(2), UpTo(8), Ruler(true)))
+	assert.Equal(
+		t,
+		"0  1\n1  4",
+		Sprint(Sqrt(2), UpTo(2), Ruler(true), Vertical(true)))
+	assert.Equal(
+		t,
+		"0  14142135",
+		Sprint(Sqrt(2), UpTo(8), Ruler(true), DigitsPerRow(0),
+			DigitsPerColumn(0), LeadingDecimal(false)))
+}
+
+func TestPrinterFooter(t *testing.T) {
+	n := fakeNumber()
+	expected := "  0.12345 67890\n10  12345\nThe end"
+	assert.Equal(
+		t,
+		expected,
+		Sprint(n, UpTo(15), DigitsPerRow(10), Footer("The end")))
+}
+
+func TestPrinterMaxBytes(t *testing.T) {
+	var sb strings.Builder
+	written, err := Fprint(&sb, Sqrt(2), UpTo(1000), MaxBytes(130))
+	assert.NoError(t, err)
+	expected := "   0.14142 13562 37309 50488 01688 72420 96980 78569 67187 53769\n" +
+		" 50  48073 17667 97379 90732 47846 21070 38850 38753 43276 41572"
+	assert.Equal(t, expected, sb.String())
+	assert.Equal(t, len(expected), written)
+}
+
+func TestPrinterMaxBytesHugePositions(t *testing.T) {
+	actual := Sprint(
+		Sqrt(2),
+		UpTo(math.MaxInt),
+		MaxBytes(40),
+		DigitsPerRow(10),
+		ShowCount(false))
+	assert.Equal(t, "0.14142 13562\n  37309 50488\n  01688", actual)
+}
+
+func TestPrinterHighlight(t *testing.T) {
+	n := fakeNumber()
+	actual := Sprint(
+		n,
+		Between(8, 13),
+		Highlight(UpTo(11), "\x1b[1m"),
+		DigitsPerRow(10),
+		DigitsPerColumn(0))
+	expected := "  0.........\x1b[1m9\x1b[0m\x1b[1m0\x1b[0m\n" +
+		"10  \x1b[1m1\x1b[0m23"
+	assert.Equal(t, expected, actual)
+}
+
+func TestPrintMatches(t *testing.T) {
+	var sb strings.Builder
+	written, err := FprintMatches(
+		&sb, Sqrt(2).WithEnd(20), []int{2, 1}, 3, 2)
+	assert.NoError(t, err)
+	expected := "0..414[2] [1]35"
+	assert.Equal(t, expected, sb.String())
+	assert.Equal(t, len(expected), written)
+}
+
+func TestPrintMatchesOverlapAndRows(t *testing.T) {
+	var sb strings.Builder
+	FprintMatches(
+		&sb,
+		fakeNumber().WithEnd(30),
+		[]int{1, 2},
+		1,
+		1,
+		DigitsPerRow(10),
+		DigitsPerColumn(0))
+	expected := "  0.[12]3......0\n" +
+		"10  [12]3......0\n" +
+		"20  [12]3"
+	assert.Equal(t, expected, sb.String())
+	sb.Reset()
+	FprintMatches(
+		&sb, Sqrt(2).WithEnd(60), []int{1, 4}, 0, 1, DigitsPerRow(10))
+	assert.Equal(t, "0.[1414]2", sb.String())
+	sb.Reset()
+	FprintMatches(&sb, Sqrt(2).WithEnd(60), []int{5, 5, 5}, 0, 1)
+	assert.Equal(t, "", sb.String())
+}
+
+func TestPrintSideBySide(t *testing.T) {
+	var sb strings.Builder
+	written, err := FprintSideBySide(
+		&sb, Sqrt(2), Sqrt(3), UpTo(50), DigitsPerRow(10))
+	assert.NoError(t, err)
+	expected := ` 0  14142 13562 | 17320 50807
+10  37309 50488 | 56887 72935
+20  01688 72420 | 27446 34150
+30  96980 78569 | 58723 66942
+40  67187 53769 | 80525 38103`
+	assert.Equal(t, expected, sb.String())
+	assert.Equal(t, len(expected), written)
+}
+
+func TestPrintSideBySideIgnoresLeadingDecimal(t *testing.T) {
+	var sb strings.Builder
+	FprintSideBySide(
+		&sb, Sqrt(2), Sqrt(3), UpTo(10), DigitsPerRow(10), LeadingDecimal(true))
+	assert.Equal(t, "0  14142 13562 | 17320 50807", sb.String())
+}
+
+func TestPrintSideBySideMissingDigits(t *testing.T) {
+	var pb PositionsBuilder
+	pb.AddRange(2, 4).AddRange(35, 37)
+	var sb strings.Builder
+	FprintSideBySide(
+		&sb,
+		fakeNumber(),
+		Sqrt(100489),
+		pb.Build(),
+		DigitsPerRow(10),
+		DigitsPerColumn(0),
+		MissingDigit('-'),
+		TrailingLF(true))
+	expected := ` 0  --34------ | --7-------
+30  -----67--- |
+`
+	assert.Equal(t, expected, sb.String())
+}
+
+func TestPrintSideBySideNoCount(t *testing.T) {
+	var sb strings.Builder
+	FprintSideBySide(
+		&sb,
+		fakeNumber().WithEnd(3),
+		fakeNumber(),
+		UpTo(12),
+		DigitsPerRow(6),
+		DigitsPerColumn(3),
+		ShowCount(false))
+	expected := `123 ... | 123 456
+        | 789 012`
+	assert.Equal(t, expected, sb.String())
+}
+
+func TestPrinterShowValue(t *testing.T) {
+	n := Sqrt(2)
+	actual := Sprint(n, UpTo(5), ShowValue(true))
+	assert.Equal(t, n.String()+"\n0.14142", actual)
+}
+
+func TestForEach(t *testing.T) {
+	n := Sqrt(2)
+	count := 0
+	ForEach(n, 200, func(posit, digit int) bool {
+		assert.Equal(t, count, posit)
+		assert.Equal(t, n.At(posit), digit)
+		count++
+		return true
+	})
+	assert.Equal(t, 200, count)
+}
+
+func TestForEachWithStart(t *testing.T) {
+	var positions []int
+	ForEach(fakeNumber().WithStart(7), 10, func(posit, digit int) bool {
+		assert.Equal(t, (posit+1)%10, digit)
+		positions = append(positions, posit)
+		return true
+	})
+	assert.Equal(t, []int{7, 8, 9}, positions)
+}
+
+func TestForEachStopEarly(t *testing.T) {
+	var digits []int
+	ForEach(Sqrt(2), 200, func(posit, digit int) bool {
+		digits = append(digits, digit)
+		return posit < 4
+	})
+	assert.Equal(t, []int{1, 4, 1, 4, 2}, digits)
+}
+
+func TestForEachReverse(t *testing.T) {
+	n := Sqrt(7).WithSignificant(6)
+	var positions, digits []int
+	ForEachReverse(n, func(posit, digit int) bool {
+		positions = append(positions, posit)
+		digits = append(digits, digit)
+		return true
+	})
+	assert.Equal(t, []int{5, 4, 3, 2, 1, 0}, positions)
+	assert.Equal(t, exhaust(n.Reverse(), 0), digits)
+}
+
+func TestForEachReverseStopEarly(t *testing.T) {
+	var digits []int
+	ForEachReverse(Sqrt(7).WithSignificant(6), func(posit, digit int) bool {
+		digits = append(digits, digit)
+		return posit > 3
+	})
+	assert.Equal(t, []int{5, 7, 5}, digits)
+}
+
+func TestForEachFinite(t *testing.T) {
+	count := 0
+	ForEach(Sqrt(100489), 200, func(posit, digit int) bool {
+		count++
+		return true
+	})
+	assert.Equal(t, 3, count)
+}
+
 type maxBytesWriter struct {
 	maxBytes     int
 	bytesWritten int
@@ -0,0 +1,46 @@
+package sqroot
+
+import (
+	"errors"
+	"io"
+)
+
+// RuneReader returns an io.RuneScanner that reads the digits of s as the
+// runes '0' through '9'. The returned io.RuneScanner can unread the last
+// rune read. If s has an infinite number of digits, the returned
+// io.RuneScanner never returns io.EOF.
+func RuneReader(s Sequence) io.RuneScanner {
+	return &runeReader{iter: s.Iterator()}
+}
+
+type runeReader struct {
+	iter      func() (Digit, bool)
+	last      rune
+	canUnread bool
+	unread    bool
+}
+
+func (r *runeReader) ReadRune() (ch rune, size int, err error) {
+	if r.unread {
+		r.unread = false
+		r.canUnread = true
+		return r.last, 1, nil
+	}
+	d, ok := r.iter()
+	if !ok {
+		r.canUnread = false
+		return 0, 0, io.EOF
+	}
+	r.last = '0' + rune(d.Value)
+	r.canUnread = true
+	return r.last, 1, nil
+}
+
+func (r *runeReader) UnreadRune() error {
+	if !r.canUnread {
+		return errors.New("UnreadRune: previous operation was not a successful ReadRune")
+	}
+	r.canUnread = false
+	r.unread = true
+	return nil
+}
@@ -0,0 +1,55 @@
+package sqroot
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRuneReader(t *testing.T) {
+	r := RuneReader(Sqrt(2).WithEnd(5))
+	assert.Error(t, r.UnreadRune())
+	assertReadRune(t, r, '1')
+	assertReadRune(t, r, '4')
+	assert.NoError(t, r.UnreadRune())
+	assert.Error(t, r.UnreadRune())
+	assertReadRune(t, r, '4')
+	assertReadRune(t, r, '1')
+	assertReadRune(t, r, '4')
+	assert.NoError(t, r.UnreadRune())
+	assertReadRune(t, r, '4')
+	assertReadRune(t, r, '2')
+	assertReadRuneEOF(t, r)
+	assert.Error(t, r.UnreadRune())
+	assertReadRuneEOF(t, r)
+}
+
+func TestRuneReaderInfinite(t *testing.T) {
+	r := RuneReader(fakeNumber().WithStart(8))
+	for _, expected := range "9012345678901" {
+		assertReadRune(t, r, expected)
+	}
+}
+
+func TestRuneReaderEmpty(t *testing.T) {
+	var n FiniteNumber
+	r := RuneReader(&n)
+	assertReadRuneEOF(t, r)
+	assert.Error(t, r.UnreadRune())
+}
+
+func assertReadRune(t *testing.T, r io.RuneReader, expected rune) {
+	t.Helper()
+	ch, size, err := r.ReadRune()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, size)
+	assert.Equal(t, expected, ch)
+}
+
+func assertReadRuneEOF(t *testing.T, r io.RuneReader) {
+	t.Helper()
+	_, size, err := r.ReadRune()
+	assert.Equal(t, io.EOF, err)
+	assert.Zero(t, size)
+}
@@ -8,9 +8,10 @@
 // number of digits. A *FiniteNumber can be used anywhere a Number type
 // is expected but not the other way around.
 //
-// A Sequence is a view of a contiguous subset of digits of a Number.
-// For example, A Sequence could represent everything past the 1000th digit
-// of the square root of 3. Because Sequences are views, they are cheap to
+// A Sequence is a view of a subset of digits of a Number. Usually the
+// subset is contiguous. For example, A Sequence could represent everything
+// past the 1000th digit of the square root of 3. Positions.Filter returns
+// the one kind of Sequence that can have gaps. Because Sequences are views, they are cheap to
 // create. Note that Number and *FiniteNumber can be used anywhere a Sequence
 // type is expected. A Sequence can be either infinite or finite in length.
 //
@@ -23,13 +24,18 @@
 package sqroot
 
 import (
+	"cmp"
 	"errors"
 	"fmt"
 	"io"
 	"iter"
 	"math"
 	"math/big"
+	"runtime"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/keep94/consume2"
 )
@@ -114,58 +120,477 @@ type Number interface {
 	// IsZero returns true if this Number is zero.
 	IsZero() bool
 
+	// PointOffset returns the 0 based index of the decimal point when this
+	// Number is printed in fixed point notation with sigDigits significant
+	// digits. That is the same as printing with %.Nf where N is sigDigits
+	// minus the exponent. For values less than 1, PointOffset returns 1
+	// to account for the leading "0." If sigDigits is so small that there
+	// would be no decimal point, PointOffset returns -1.
+	PointOffset(sigDigits int) int
+
+	// AsFraction returns the closest convergent of the continued fraction
+	// of this Number that has a denominator no bigger than maxDenominator.
+	// For example, AsFraction(100) on the square root of 2 returns 99/70.
+	// AsFraction also ensures that the numerator fits in an int64. If no
+	// convergent satisfies these constraints, AsFraction returns (0, 0).
+	// AsFraction panics if maxDenominator is not positive.
+	AsFraction(maxDenominator int64) (num, denom int64)
+
+	// HasPrefix returns true if the digits of this Number's mantissa begin
+	// with digits. HasPrefix ignores the decimal point and exponent. For
+	// example, the square root of 2 has the prefix "14142". HasPrefix
+	// returns true if digits is empty.
+	HasPrefix(digits string) bool
+
+	// AppendFormat appends this Number formatted with verb and precision
+	// to dst and returns the extended buffer. verb is one of f, F, g, G,
+	// e, or E and works the same way as it does in Format. A negative
+	// precision means use the same default precision that Format uses.
+	// If verb is not supported, AppendFormat appends '%' followed by verb
+	// the same way strconv.AppendFloat does.
+	AppendFormat(dst []byte, verb byte, precision int) []byte
+
+	// Sqrt returns the square root of this Number. Sqrt computes the
+	// digits of the returned Number lazily from the digits of this Number.
+	// Computing the first N significant digits of the returned Number
+	// requires computing about the first 2N significant digits of this
+	// Number.
+	Sqrt() Number
+
+	// DigitsKnown returns how many significant digits of this Number have
+	// been computed so far. complete is true if this Number has a finite
+	// number of digits and all of them have been computed. DigitsKnown
+	// never computes any digits itself.
+	DigitsKnown() (count int, complete bool)
+
+	// DigitsAsBigInt returns the integer formed by the first n significant
+	// digits of this Number ignoring the decimal point. For example,
+	// DigitsAsBigInt(5) on the square root of 2 returns 14142. If this
+	// Number has fewer than n significant digits, DigitsAsBigInt uses all
+	// of them. DigitsAsBigInt returns 0 if n is not positive.
+	DigitsAsBigInt(n int) *big.Int
+
+	// AtBase works like At except that it returns the significant digit
+	// of this Number at the given 0 based position when this Number is
+	// written in base. For example, AtBase(2, 16) on the square root of 2
+	// returns 10 because the square root of 2 is 1.6A09E667... in
+	// hexadecimal. AtBase remembers the digits it computes for each base.
+	// AtBase panics if base is not between 2 and 16. If this Number has an
+	// infinite number of decimal digits but a finite number of digits in
+	// base, such as 1/3 in base 3, AtBase gives up refining its bounds
+	// after about a thousand extra decimal digits and treats this Number
+	// as having a finite number of digits in base.
+	AtBase(posit, base int) int
+
+	// CheckRoot returns true if this Number looks like the degree root of
+	// radican when truncated to sigDigits significant digits. That is,
+	// CheckRoot returns true if radican is at least the truncated value
+	// raised to degree but less than the truncated value plus one unit in
+	// the last place raised to degree. CheckRoot panics if degree is not
+	// positive or if sigDigits is negative.
+	CheckRoot(radican *big.Rat, degree, sigDigits int) bool
+
+	// MantissaExponent returns the mantissa of this Number truncated to
+	// sigDigits significant digits along with the exponent. The mantissa
+	// is a decimal string between 0.1 inclusive and 1 exclusive such as
+	// "0.14142". A zero Number or a sigDigits of zero returns ("0", 0).
+	// MantissaExponent panics if sigDigits is negative.
+	MantissaExponent(sigDigits int) (mantissa string, exponent int)
+
+	// WithSignificantForBytes works like WithSignificant except that it
+	// uses as many significant digits as it can such that calling
+	// MarshalBinary on the returned value yields no more than maxBytes
+	// bytes. If even zero needs more than maxBytes bytes,
+	// WithSignificantForBytes returns zero.
+	WithSignificantForBytes(maxBytes int) *FiniteNumber
+
+	// AgreementLength returns how many leading digits of this Number's
+	// mantissa match the digits of literal. AgreementLength ignores the
+	// decimal point and any leading zeros in literal and stops comparing
+	// at the first character that is not a digit. For example,
+	// AgreementLength("1.41431") on the square root of 2 returns 4.
+	AgreementLength(literal string) int
+
+	// ExportState returns the state of the computation of this Number so
+	// that RestoreNumberState can resume it, possibly in another process,
+	// without recomputing the digits computed so far. The state includes
+	// the radican and all the digits computed so far. ExportState returns
+	// an error if this Number is not the result of a root function such as
+	// Sqrt or CubeRootBigRat. Views such as WithSignificant don't count as
+	// the result of a root function.
+	ExportState() ([]byte, error)
+
+	// IsExact returns true if the digits of this Number are the exact
+	// decimal representation of the value it came from rather than a
+	// truncation of more digits. For example, Sqrt(100489) is exact, but
+	// Sqrt(2).WithSignificant(10) is not. IsExact may compute one more
+	// digit than this Number has to tell whether it was truncated. If this
+	// Number may have an infinite number of digits, IsExact returns true
+	// only if all of its digits have already been computed.
+	IsExact() bool
+
+	// RoundToInt returns the value of this Number rounded to the nearest
+	// integer with halves rounded away from zero. RoundToInt reads only
+	// the first Exponent()+1 significant digits of this Number. For
+	// example, RoundToInt on the square root of 3, 1.732..., returns 2.
+	RoundToInt() *big.Int
+
+	// Tail returns the Number whose mantissa is the digits of this Number
+	// from the zero based position start on. The returned Number has an
+	// exponent of 0, so its leading digit, At(0), is the digit of this
+	// Number at start. For example, Tail(3) on the square root of 2,
+	// 1.414213..., returns 0.4213... If the digit at start is 0, Tail skips
+	// the leading zeros and gives the returned Number a negative exponent
+	// so that its value is still 0 followed by the digits from start on.
+	// If start is zero or negative, Tail returns this Number. Tail
+	// computes the digits of the returned Number lazily except for the
+	// first one.
+	Tail(start int) Number
+
+	// ExactRat returns the exact value of this Number as a big.Rat. ok is
+	// true only when IsExact returns true meaning that this Number has a
+	// finite number of digits that are not a truncation of more digits.
+	// For example, CubeRoot(8).ExactRat() returns 2/1, true while
+	// Sqrt(2).ExactRat() and Sqrt(2).WithSignificant(10).ExactRat() both
+	// return nil, false.
+	ExactRat() (value *big.Rat, ok bool)
+
+	// CmpFloat64 compares this Number to threshold and returns -1 if this
+	// Number is less than threshold, 0 if they are equal, and 1 if this
+	// Number is greater. CmpFloat64 uses the exact value of threshold and
+	// reads only as many digits of this Number as it needs to decide.
+	// Since this Number can only equal threshold if it has a finite number
+	// of digits, CmpFloat64 may never return if this Number has an
+	// infinite number of digits that are eventually all zero. CmpFloat64
+	// panics if threshold is NaN.
+	CmpFloat64(threshold float64) int
+
+	// WithMantissaExponent returns a Number with the same mantissa digits
+	// as this Number but with exponent exp. The returned Number shares
+	// digits with this Number rather than computing them again. For
+	// example, WithMantissaExponent(0) on the square root of 2 returns
+	// 0.1414213... If this Number is zero, WithMantissaExponent returns
+	// zero.
+	WithMantissaExponent(exp int) Number
+
+	// FirstSignificantOffset returns how many zeros come between the
+	// decimal point and the first nonzero digit when this Number is written
+	// in fixed point. That is -Exponent() when Exponent() <= 0 and 0
+	// otherwise. For example, FirstSignificantOffset on the square root of
+	// 0.0026, 0.05099..., returns 1. If this Number is zero,
+	// FirstSignificantOffset returns 0.
+	FirstSignificantOffset() int
+
+	// LaTeX returns this Number as a LaTeX math expression showing at most
+	// sigDigits significant digits. If this Number has more than sigDigits
+	// digits or is itself a truncation of more digits, LaTeX uses
+	// scientific notation with an ellipsis after the digits. For example,
+	// LaTeX(6) on the square root of 2 returns
+	// "1.41421\ldots \times 10^{0}". Otherwise, LaTeX writes the exact
+	// value in fixed form such as "317" for the square root of 100489 or
+	// "0.00005001" for a very small value. If this Number is zero, LaTeX
+	// returns "0". LaTeX panics if sigDigits is not positive.
+	LaTeX(sigDigits int) string
+
+	// RationalWithin returns the first convergent of the continued
+	// fraction of this Number that is less than maxErr away from this
+	// Number. Since the convergents come in order of increasing
+	// denominator, the returned value is the simplest convergent that is
+	// close enough. For example, RationalWithin(1/100000) on the square
+	// root of 2 returns 577/408. RationalWithin returns an error if maxErr
+	// is not positive.
+	RationalWithin(maxErr *big.Rat) (*big.Rat, error)
+
 	withExponent(e int) Number
 }
 
+// RootOption represents an option for the functions that compute roots
+// such as Sqrt and CubeRootBigRat.
+type RootOption interface {
+	mutateRoot(s *rootSettings)
+}
+
+// OnProgress registers fn to be called each time a new chunk of digits of
+// the root has been computed. digitsComputed is the total number of
+// significant digits computed so far. Because Number computes digits on
+// demand, fn only gets called as digits are requested. fn runs on the
+// background goroutine that computes the digits, so it must be safe to call
+// from a different goroutine and should return quickly. fn is never called
+// for roots that have a finite number of digits such as Sqrt(4) because
+// those digits are computed right away.
+func OnProgress(fn func(digitsComputed int)) RootOption {
+	return rootOptionFunc(func(s *rootSettings) {
+		s.onProgress = fn
+	})
+}
+
 // Sqrt returns the square root of radican. Sqrt panics if radican is
 // negative.
-func Sqrt(radican int64) Number {
-	return nRootFrac(big.NewInt(radican), one, newSqrtManager)
+func Sqrt(radican int64, options ...RootOption) Number {
+	return nRootFrac(big.NewInt(radican), one, newSqrtManager, options...)
+}
+
+// SqrtImaginary works like Sqrt except that it accepts negative radicans.
+// SqrtImaginary returns the square root of the absolute value of radican
+// as magnitude. imaginary is true if radican is negative meaning that the
+// square root of radican is magnitude times i.
+func SqrtImaginary(radican int64) (magnitude Number, imaginary bool) {
+	bigRadican := big.NewInt(radican)
+	imaginary = bigRadican.Sign() < 0
+	magnitude = nRootFrac(bigRadican.Abs(bigRadican), one, newSqrtManager)
+	return
 }
 
 // SqrtRat returns the square root of num / denom. denom must be positive,
 // and num must be non-negative or else SqrtRat panics.
-func SqrtRat(num, denom int64) Number {
-	return nRootFrac(big.NewInt(num), big.NewInt(denom), newSqrtManager)
+func SqrtRat(num, denom int64, options ...RootOption) Number {
+	return nRootFrac(
+		big.NewInt(num), big.NewInt(denom), newSqrtManager, options...)
 }
 
 // SqrtBigInt returns the square root of radican. SqrtBigInt panics if
 // radican is negative.
-func SqrtBigInt(radican *big.Int) Number {
-	return nRootFrac(radican, one, newSqrtManager)
+func SqrtBigInt(radican *big.Int, options ...RootOption) Number {
+	return nRootFrac(radican, one, newSqrtManager, options...)
 }
 
 // SqrtBigRat returns the square root of radican. The denominator of radican
 // must be positive, and the numerator must be non-negative or else SqrtBigRat
 // panics.
-func SqrtBigRat(radican *big.Rat) Number {
-	return nRootFrac(radican.Num(), radican.Denom(), newSqrtManager)
+func SqrtBigRat(radican *big.Rat, options ...RootOption) Number {
+	return nRootFrac(
+		radican.Num(), radican.Denom(), newSqrtManager, options...)
+}
+
+// TrySqrt works like Sqrt except that it returns an error instead of
+// panicking if radican is negative.
+func TrySqrt(radican int64, options ...RootOption) (Number, error) {
+	return tryNRootFrac(
+		"TrySqrt", big.NewInt(radican), one, newSqrtManager, options...)
+}
+
+// TrySqrtRat works like SqrtRat except that it returns an error instead of
+// panicking if denom is not positive or num is negative.
+func TrySqrtRat(num, denom int64, options ...RootOption) (Number, error) {
+	return tryNRootFrac(
+		"TrySqrtRat",
+		big.NewInt(num),
+		big.NewInt(denom),
+		newSqrtManager,
+		options...)
+}
+
+// TrySqrtBigInt works like SqrtBigInt except that it returns an error
+// instead of panicking if radican is negative.
+func TrySqrtBigInt(radican *big.Int, options ...RootOption) (Number, error) {
+	return tryNRootFrac(
+		"TrySqrtBigInt", radican, one, newSqrtManager, options...)
+}
+
+// TrySqrtBigRat works like SqrtBigRat except that it returns an error
+// instead of panicking if radican is negative.
+func TrySqrtBigRat(radican *big.Rat, options ...RootOption) (Number, error) {
+	return tryNRootFrac(
+		"TrySqrtBigRat",
+		radican.Num(),
+		radican.Denom(),
+		newSqrtManager,
+		options...)
+}
+
+// SqrtBigFloat returns the square root of radican. SqrtBigFloat uses the
+// exact value of radican, so the precision of radican affects only which
+// value radican holds. SqrtBigFloat panics if radican is negative or
+// infinite.
+func SqrtBigFloat(radican *big.Float) Number {
+	if radican.IsInf() {
+		panic("radican must be finite")
+	}
+	rat, _ := radican.Rat(nil)
+	return SqrtBigRat(rat)
+}
+
+// IntSqrt returns the floor of the square root of radican as a new
+// big.Int. IntSqrt is much faster than SqrtBigInt when only the integer part
+// is needed because it does not compute any digits. IntSqrt panics if
+// radican is negative.
+func IntSqrt(radican *big.Int) *big.Int {
+	if radican.Sign() < 0 {
+		panic("radican must be non-negative")
+	}
+	return new(big.Int).Sqrt(radican)
+}
+
+// IntSqrtRem works like IntSqrt except that it also returns the remainder,
+// radican - root*root.
+func IntSqrtRem(radican *big.Int) (root, remainder *big.Int) {
+	root = IntSqrt(radican)
+	remainder = new(big.Int).Mul(root, root)
+	remainder.Sub(radican, remainder)
+	return
+}
+
+// SqrtToError returns the square root of radican truncated to just enough
+// significant digits that the truncation error is less than relErr times
+// the value of the square root. For example, SqrtToError(2,
+// big.NewRat(1, 1000)) returns 1.414. SqrtToError panics if radican is
+// negative or if relErr is not positive.
+func SqrtToError(radican int64, relErr *big.Rat) *FiniteNumber {
+	if relErr.Sign() <= 0 {
+		panic("relErr must be positive")
+	}
+	return Sqrt(radican).WithSignificant(sigDigitsForError(relErr))
+}
+
+// SqrtAll returns the square roots of radicans truncated to sigDigits
+// significant digits. SqrtAll computes the roots concurrently using at
+// most runtime.GOMAXPROCS(0) goroutines, and the digits of each returned
+// FiniteNumber are already computed. The ith returned FiniteNumber is the
+// square root of radicans[i]. Rather than panicking, SqrtAll skips any
+// negative radican and returns zero for it. SqrtAll panics if sigDigits is
+// negative.
+func SqrtAll(radicans []int64, sigDigits int) []*FiniteNumber {
+	if sigDigits < 0 {
+		panic("sigDigits must be non-negative")
+	}
+	result := make([]*FiniteNumber, len(radicans))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for range min(runtime.GOMAXPROCS(0), len(radicans)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				if radicans[i] < 0 {
+					result[i] = zeroNumber
+					continue
+				}
+				root := Sqrt(radicans[i]).WithSignificant(sigDigits)
+				root.mantissa.allDigits()
+				result[i] = root
+			}
+		}()
+	}
+	for i := range radicans {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	return result
+}
+
+// SqrtCache hands out square roots so that asking for the square root of
+// the same radican more than once returns the same Number each time. Since
+// a Number remembers the digits it has computed, sharing one Number for
+// each radican means its digits get computed only once for the whole
+// program. SqrtCache is safe to use with multiple goroutines. Use Cached
+// to create a SqrtCache.
+type SqrtCache struct {
+	mu    sync.Mutex
+	roots map[int64]Number
+}
+
+// Cached returns a new, empty SqrtCache.
+func Cached() *SqrtCache {
+	return &SqrtCache{roots: make(map[int64]Number)}
+}
+
+// Sqrt works like the Sqrt function except that it returns the same
+// Number each time it is called with the same radican. Sqrt panics if
+// radican is negative.
+func (c *SqrtCache) Sqrt(radican int64) Number {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if result, ok := c.roots[radican]; ok {
+		return result
+	}
+	result := Sqrt(radican)
+	c.roots[radican] = result
+	return result
 }
 
 // CubeRoot returns the cube root of radican. CubeRoot panics if radican is
 // negative as Number can only hold positive results.
-func CubeRoot(radican int64) Number {
-	return nRootFrac(big.NewInt(radican), one, newCubeRootManager)
+func CubeRoot(radican int64, options ...RootOption) Number {
+	return nRootFrac(big.NewInt(radican), one, newCubeRootManager, options...)
 }
 
 // CubeRootRat returns the cube root of num / denom. Because Number can only
 // hold positive results, denom must be positive, and num must be non-negative
 // or else CubeRootRat panics.
-func CubeRootRat(num, denom int64) Number {
-	return nRootFrac(big.NewInt(num), big.NewInt(denom), newCubeRootManager)
+func CubeRootRat(num, denom int64, options ...RootOption) Number {
+	return nRootFrac(
+		big.NewInt(num), big.NewInt(denom), newCubeRootManager, options...)
 }
 
 // CubeRootBigInt returns the cube root of radican. CubeRootBigInt panics if
 // radican is negative as Number can only hold positive results.
-func CubeRootBigInt(radican *big.Int) Number {
-	return nRootFrac(radican, one, newCubeRootManager)
+func CubeRootBigInt(radican *big.Int, options ...RootOption) Number {
+	return nRootFrac(radican, one, newCubeRootManager, options...)
 }
 
 // CubeRootBigRat returns the cube root of radican. Because Number can only
 // hold positive results, the denominator of radican must be positive, and the
 // numerator must be non-negative or else CubeRootBigRat panics.
-func CubeRootBigRat(radican *big.Rat) Number {
-	return nRootFrac(radican.Num(), radican.Denom(), newCubeRootManager)
+func CubeRootBigRat(radican *big.Rat, options ...RootOption) Number {
+	return nRootFrac(
+		radican.Num(), radican.Denom(), newCubeRootManager, options...)
+}
+
+// GeometricMean returns the geometric mean of a and b, the square root of
+// a*b. GeometricMean does not overflow when a*b is too big for an int64.
+// GeometricMean panics if a or b is negative.
+func GeometricMean(a, b int64) Number {
+	if a < 0 || b < 0 {
+		panic("GeometricMean arguments must be non-negative")
+	}
+	product := new(big.Int).Mul(big.NewInt(a), big.NewInt(b))
+	return nRootFrac(product, one, newSqrtManager)
+}
+
+// QuadraticMean returns the quadratic mean, or root mean square, of values.
+// That is the square root of the sum of the squares of values divided by
+// len(values). QuadraticMean does not overflow when the sum of the squares
+// is too big for an int64. If values is empty, QuadraticMean returns zero.
+func QuadraticMean(values []int64) Number {
+	if len(values) == 0 {
+		return zeroNumber
+	}
+	var sum, square big.Int
+	for _, value := range values {
+		square.SetInt64(value)
+		sum.Add(&sum, square.Mul(&square, &square))
+	}
+	return nRootFrac(&sum, big.NewInt(int64(len(values))), newSqrtManager)
+}
+
+// Hypot returns the square root of a*a + b*b, the length of the hypotenuse
+// of a right triangle with legs a and b. Hypot does not overflow when
+// a*a + b*b is too big for an int64. Negative a or b are squared like any
+// other value.
+func Hypot(a, b int64) Number {
+	var sum, square big.Int
+	square.SetInt64(a)
+	sum.Mul(&square, &square)
+	square.SetInt64(b)
+	sum.Add(&sum, square.Mul(&square, &square))
+	return nRootFrac(&sum, one, newSqrtManager)
+}
+
+// AGM returns the result of starting with x = a and y = b and then
+// replacing x and y with (x+y)/2 and sqrt(x*y) iterations times. The
+// returned Number is the final value of x. As iterations increases, the
+// returned Number converges quickly to the arithmetic-geometric mean of a
+// and b. AGM computes the digits of the returned Number exactly. AGM panics
+// if a, b, or iterations is negative.
+func AGM(a, b int64, iterations int) Number {
+	if a < 0 || b < 0 || iterations < 0 {
+		panic("AGM arguments must be non-negative")
+	}
+	if a == 0 && (b == 0 || iterations == 0) {
+		return zeroNumber
+	}
+	return NewNumber(&agmGenerator{a: a, b: b, iterations: iterations})
 }
 
 // NewNumberFromBigRat returns value as a Number. Because Number can only
@@ -224,6 +649,83 @@ func NewNumber(g Generator) Number {
 	return newNumber(firstAndThen(first, digits), exp)
 }
 
+// NewNumberFromDigitFunc returns a new Number whose mantissa digits come
+// from f. f(i) returns the zero based ith digit of the mantissa or -1 if
+// the mantissa has no more digits. The returned Number is
+// mantissa*10^exp where mantissa is between 0.1 inclusive and 1.0
+// exclusive. NewNumberFromDigitFunc calls f with i = 0, 1, 2, ... in order
+// and only as more digits are needed. If f returns a value outside of 0
+// and 9 for any i other than 0, NewNumberFromDigitFunc regards that as the
+// end of the mantissa. If f(0) is -1, NewNumberFromDigitFunc returns zero.
+// NewNumberFromDigitFunc returns an error if f(0) is 0 or any other value
+// outside of 1 and 9.
+func NewNumberFromDigitFunc(f func(i int) int, exp int) (Number, error) {
+	first := f(0)
+	if first == -1 {
+		return zeroNumber, nil
+	}
+	if first == 0 {
+		return nil, errors.New("NewNumberFromDigitFunc: leading zeros not allowed in digits")
+	}
+	if digitOutOfRange(first) {
+		return nil, errors.New("NewNumberFromDigitFunc: digits must be between 0 and 9")
+	}
+	i := 0
+	digits := func() int {
+		i++
+		return f(i)
+	}
+	return newNumber(firstAndThen(first, digits), exp), nil
+}
+
+// NewNumberFromChannel returns a new Number whose mantissa digits are read
+// from ch. The returned Number is mantissa*10^exp where mantissa is between
+// 0.1 inclusive and 1.0 exclusive. The mantissa ends when ch is closed or
+// when a value outside of 0 and 9 such as -1 arrives. The first digit
+// received must be nonzero; if it is 0, or if the mantissa has no digits,
+// NewNumberFromChannel returns zero. NewNumberFromChannel blocks until the
+// first digit arrives, but after that it reads from ch only as more digits
+// are needed.
+func NewNumberFromChannel(ch <-chan int, exp int) Number {
+	digits := func() int {
+		digit, ok := <-ch
+		if !ok {
+			return -1
+		}
+		return digit
+	}
+	first := digits()
+	if first == 0 || digitOutOfRange(first) {
+		return zeroNumber
+	}
+	return newNumber(firstAndThen(first, digits), exp)
+}
+
+// DigitsForPrecision returns the number of significant digits of n needed
+// to get decimalPlaces digits after the decimal point. Passing the returned
+// value to n.WithSignificant gives a Number accurate to within
+// 10^-decimalPlaces. For example, DigitsForPrecision(Sqrt(2), 50) returns 51
+// because sqrt(2) has one digit before the decimal point. DigitsForPrecision
+// never returns a negative value.
+func DigitsForPrecision(n Number, decimalPlaces int) int {
+	return max(0, decimalPlaces+n.Exponent())
+}
+
+// CompareWithin returns a function that compares two Numbers using only
+// their first sigDigits significant digits. The returned function returns
+// a negative number if a < b, 0 if a == b, and a positive number if a > b
+// which makes it suitable for slices.SortFunc. CompareWithin panics if
+// sigDigits is negative.
+func CompareWithin(sigDigits int) func(a, b Number) int {
+	if sigDigits < 0 {
+		panic("sigDigits must be non-negative")
+	}
+	return func(a, b Number) int {
+		return compareFinite(
+			a.WithSignificant(sigDigits), b.WithSignificant(sigDigits))
+	}
+}
+
 // FiniteNumber is a Number with a finite number of digits. FiniteNumber
 // implements both Number and FiniteSequence. The zero value for FiniteNumber
 // is 0.
@@ -233,6 +735,8 @@ func NewNumber(g Generator) Number {
 type FiniteNumber struct {
 	mantissa mantissa
 	exponent int
+	bases    map[int]*baseExpansion
+	source   *rootSource
 }
 
 // NewFiniteNumber works like NewNumberForTesting except that it
@@ -305,6 +809,61 @@ func (n *FiniteNumber) Exact() string {
 	return builder.String()
 }
 
+// ExactParts returns the same value as Exact broken into parts so that
+// callers don't have to parse Exact's output. intPart and fracPart are the
+// digits before and after the decimal point. exp is the exponent when
+// Exact uses scientific notation and 0 otherwise. For example, if Exact
+// returns "0.5001e-04", ExactParts returns ("0", "5001", -4); if Exact
+// returns "500.1", ExactParts returns ("500", "1", 0). The zero value
+// returns ("0", "", 0).
+func (n *FiniteNumber) ExactParts() (intPart, fracPart string, exp int) {
+	str := n.Exact()
+	if mantissa, exponent, ok := strings.Cut(str, "e"); ok {
+		str = mantissa
+		exp, _ = strconv.Atoi(exponent)
+	}
+	intPart, fracPart, _ = strings.Cut(str, ".")
+	return
+}
+
+// Reversed returns a FiniteNumber with the same exponent as n but with the
+// digits of n's mantissa in reverse order. For example, if n is 317,
+// Reversed returns 713. If n's mantissa ends in zeros, the reversed
+// mantissa would begin with zeros, so Reversed returns the same value
+// with those zeros dropped from the mantissa and the exponent reduced
+// instead. For example, if n is 0.120, Reversed returns 0.021. The zero
+// value reverses to itself.
+func (n *FiniteNumber) Reversed() *FiniteNumber {
+	digits := n.mantissa.allDigits()
+	reversed := make([]int8, 0, len(digits))
+	for i := len(digits) - 1; i >= 0; i-- {
+		if len(reversed) == 0 && digits[i] == 0 {
+			continue
+		}
+		reversed = append(reversed, digits[i])
+	}
+	if len(reversed) == 0 {
+		return n
+	}
+	return &FiniteNumber{
+		mantissa: mantissa{spec: &staticSpec{data: reversed}},
+		exponent: n.exponent - (len(digits) - len(reversed)),
+	}
+}
+
+// TrailingZeros returns the number of zero digits at the end of n's
+// mantissa. For example, if n was built with NewFiniteNumber from the
+// digits 1, 2, 0, 0 it returns 2, but if n is 500.1 it returns 0. The zero
+// value returns 0.
+func (n *FiniteNumber) TrailingZeros() int {
+	digits := n.mantissa.allDigits()
+	count := 0
+	for i := len(digits) - 1; i >= 0 && digits[i] == 0; i-- {
+		count++
+	}
+	return count
+}
+
 // String comes from the Number interface.
 func (n *FiniteNumber) String() string {
 	var builder strings.Builder
@@ -318,6 +877,267 @@ func (n *FiniteNumber) IsZero() bool {
 	return n.mantissa.IsZero()
 }
 
+// PointOffset comes from the Number interface.
+func (n *FiniteNumber) PointOffset(sigDigits int) int {
+	if sigDigits <= n.exponent {
+		return -1
+	}
+	return max(n.exponent, 1)
+}
+
+// AsFraction comes from the Number interface.
+func (n *FiniteNumber) AsFraction(maxDenominator int64) (num, denom int64) {
+	if maxDenominator <= 0 {
+		panic("maxDenominator must be positive")
+	}
+	bigMax := big.NewInt(maxDenominator)
+	value := truncatedRat(n, digitsForDenominator(n, bigMax))
+	for p, q := range convergents(value) {
+		if q.Cmp(bigMax) > 0 || !p.IsInt64() {
+			break
+		}
+		num, denom = p.Int64(), q.Int64()
+	}
+	return
+}
+
+// HasPrefix comes from the Number interface.
+func (n *FiniteNumber) HasPrefix(digits string) bool {
+	index := 0
+	for value := range n.WithSignificant(len(digits)).Values() {
+		if digits[index] != '0'+byte(value) {
+			return false
+		}
+		index++
+	}
+	return index == len(digits)
+}
+
+// AppendFormat comes from the Number interface.
+func (n *FiniteNumber) AppendFormat(
+	dst []byte, verb byte, precision int) []byte {
+	formatSpec, ok := formatSpecForVerb(
+		rune(verb), precision, precision >= 0, n.exponent)
+	if !ok {
+		return append(dst, '%', verb)
+	}
+	writer := &appendWriter{buffer: dst}
+	formatSpec.PrintNumber(writer, n)
+	return writer.buffer
+}
+
+// Sqrt comes from the Number interface.
+func (n *FiniteNumber) Sqrt() Number {
+	if n.IsZero() {
+		return zeroNumber
+	}
+	return newNumber((&numberSqrtGenerator{number: n}).Generate())
+}
+
+// DigitsKnown comes from the Number interface.
+func (n *FiniteNumber) DigitsKnown() (count int, complete bool) {
+	return n.mantissa.Known()
+}
+
+// DigitsAsBigInt comes from the Number interface.
+func (n *FiniteNumber) DigitsAsBigInt(count int) *big.Int {
+	result := new(big.Int)
+	if count <= 0 {
+		return result
+	}
+	var digit big.Int
+	for value := range n.WithSignificant(count).Values() {
+		result.Mul(result, ten)
+		result.Add(result, digit.SetInt64(int64(value)))
+	}
+	return result
+}
+
+// CheckRoot comes from the Number interface.
+func (n *FiniteNumber) CheckRoot(radican *big.Rat, degree, sigDigits int) bool {
+	if degree <= 0 {
+		panic("degree must be positive")
+	}
+	lower := truncatedRat(n, sigDigits)
+	upper := scaleByPowerOf10(big.NewRat(1, 1), n.exponent-sigDigits)
+	upper.Add(upper, lower)
+	return ratPower(lower, degree).Cmp(radican) <= 0 &&
+		ratPower(upper, degree).Cmp(radican) > 0
+}
+
+// MantissaExponent comes from the Number interface.
+func (n *FiniteNumber) MantissaExponent(sigDigits int) (
+	mantissa string, exponent int) {
+	truncated := n.WithSignificant(sigDigits)
+	if truncated.IsZero() {
+		return "0", 0
+	}
+	return "0." + DigitsToString(truncated), truncated.exponent
+}
+
+// WithSignificantForBytes comes from the Number interface.
+func (n *FiniteNumber) WithSignificantForBytes(maxBytes int) *FiniteNumber {
+	tooBig := sort.Search(2*max(maxBytes, 0)+1, func(count int) bool {
+		return marshaledSize(count, n.exponent) > maxBytes
+	})
+	return n.WithSignificant(max(tooBig-1, 0))
+}
+
+// AgreementLength comes from the Number interface.
+func (n *FiniteNumber) AgreementLength(literal string) int {
+	digits := strings.TrimLeft(strings.Replace(literal, ".", "", 1), "0")
+	result := 0
+	for value := range n.Values() {
+		if result == len(digits) || digits[result] != '0'+byte(value) {
+			break
+		}
+		result++
+	}
+	return result
+}
+
+// IsExact comes from the Number interface.
+func (n *FiniteNumber) IsExact() bool {
+	return n.mantissa.IsExact()
+}
+
+// RoundToInt comes from the Number interface.
+func (n *FiniteNumber) RoundToInt() *big.Int {
+	result := new(big.Int)
+	if n.exponent < 0 {
+		return result
+	}
+	result.Set(truncatedRat(n, n.exponent).Num())
+	if n.At(n.exponent) >= 5 {
+		result.Add(result, one)
+	}
+	return result
+}
+
+// Tail comes from the Number interface.
+func (n *FiniteNumber) Tail(start int) Number {
+	if start <= 0 {
+		return n
+	}
+	iter := n.mantissa.IteratorAt(start)
+	digits := func() int {
+		d, ok := iter()
+		if !ok {
+			return -1
+		}
+		return d.Value
+	}
+	exp := 0
+	first := digits()
+	for first == 0 {
+		first = digits()
+		exp--
+	}
+	if first == -1 {
+		return zeroNumber
+	}
+	return newFiniteNumber(firstAndThen(first, digits), exp)
+}
+
+// ExactRat comes from the Number interface.
+func (n *FiniteNumber) ExactRat() (value *big.Rat, ok bool) {
+	if !n.IsExact() {
+		return nil, false
+	}
+	return truncatedRat(n, len(n.mantissa.allDigits())), true
+}
+
+// CmpFloat64 comes from the Number interface.
+func (n *FiniteNumber) CmpFloat64(threshold float64) int {
+	if math.IsNaN(threshold) {
+		panic("threshold must not be NaN")
+	}
+	if threshold <= 0 {
+		if threshold == 0 && n.IsZero() {
+			return 0
+		}
+		return 1
+	}
+	if math.IsInf(threshold, 1) {
+		return -1
+	}
+	value := NewNumberFromBigRat(new(big.Rat).SetFloat64(threshold))
+	finite := value.WithSignificant(math.MaxInt)
+	count := len(finite.mantissa.allDigits())
+	if c := compareFinite(n.WithSignificant(count), finite); c != 0 {
+		return c
+	}
+	for digit := range n.WithStart(count).Values() {
+		if digit != 0 {
+			return 1
+		}
+	}
+	return 0
+}
+
+// WithMantissaExponent comes from the Number interface.
+func (n *FiniteNumber) WithMantissaExponent(exp int) Number {
+	return n.withExponent(exp)
+}
+
+// FirstSignificantOffset comes from the Number interface.
+func (n *FiniteNumber) FirstSignificantOffset() int {
+	if n.IsZero() {
+		return 0
+	}
+	return max(-n.exponent, 0)
+}
+
+// LaTeX comes from the Number interface.
+func (n *FiniteNumber) LaTeX(sigDigits int) string {
+	if sigDigits <= 0 {
+		panic("sigDigits must be positive")
+	}
+	if n.IsZero() {
+		return "0"
+	}
+	truncated := n.WithSignificant(sigDigits)
+	var builder strings.Builder
+	if truncated.IsExact() {
+		fs := formatSpec{sigDigits: max(endOf(truncated), truncated.exponent)}
+		fs.printFixed(&builder, truncated.mantissa, truncated.exponent)
+		return builder.String()
+	}
+	digits := DigitsToString(truncated)
+	builder.WriteString(digits[:1])
+	if len(digits) > 1 {
+		builder.WriteByte('.')
+		builder.WriteString(digits[1:])
+	}
+	fmt.Fprintf(&builder, `\ldots \times 10^{%d}`, n.exponent-1)
+	return builder.String()
+}
+
+// RationalWithin comes from the Number interface.
+func (n *FiniteNumber) RationalWithin(maxErr *big.Rat) (*big.Rat, error) {
+	if maxErr.Sign() <= 0 {
+		return nil, errors.New("RationalWithin: maxErr must be positive")
+	}
+	digits := digitsForError(n, maxErr)
+	value := truncatedRat(n, digits)
+
+	// This Number exceeds value by less than truncation.
+	truncation := scaleByPowerOf10(big.NewRat(1, 1), n.Exponent()-digits)
+	var diff big.Rat
+	for p, q := range convergents(value) {
+		result := new(big.Rat).SetFrac(p, q)
+		diff.Sub(value, result)
+		diff.Abs(&diff)
+		if diff.Add(&diff, truncation).Cmp(maxErr) <= 0 {
+			return result, nil
+		}
+	}
+
+	// The last convergent equals value, and truncation is much less than
+	// maxErr, so we never get here.
+	panic("RationalWithin: no convergent within maxErr")
+}
+
 // Iterator comes from the Sequence interface.
 func (n *FiniteNumber) Iterator() func() (Digit, bool) {
 	return n.mantissa.IteratorAt(0)
@@ -369,13 +1189,58 @@ func (n *FiniteNumber) withMantissa(newMantissa mantissa) *FiniteNumber {
 func (n *FiniteNumber) private() {
 }
 
+// sigDigitsForError returns the fewest significant digits such that
+// truncating any Number to that many digits gives a relative error less
+// than relErr. Truncating to k significant digits gives a relative error
+// less than 10^(1-k), so sigDigitsForError returns the smallest k with
+// 10^(k-1) >= 1/relErr.
+func sigDigitsForError(relErr *big.Rat) int {
+	power := new(big.Int).Set(relErr.Num())
+	result := 1
+	for power.Cmp(relErr.Denom()) < 0 {
+		power.Mul(power, ten)
+		result++
+	}
+	return result
+}
+
 func nRootFrac(
-	num, denom *big.Int, newManager func() rootManager) Number {
+	num, denom *big.Int,
+	newManager func() rootManager,
+	options ...RootOption) Number {
 	checkNumDenom(num, denom)
 	if num.Sign() == 0 {
 		return zeroNumber
 	}
-	return newNumber(newNRootGenerator(num, denom, newManager).Generate())
+	gen := newNRootGenerator(num, denom, newManager)
+	source := newRootSource(num, denom, newManager)
+	if exactRoot(num, denom, source.degree) {
+		result := newStaticFiniteNumber(gen.Generate())
+		result.source = source
+		return opaqueNumber(result)
+	}
+	var settings rootSettings
+	for _, option := range options {
+		option.mutateRoot(&settings)
+	}
+	digits, exp := gen.Generate()
+	result := &FiniteNumber{
+		mantissa: mantissa{
+			spec: newMemoizeSpecFrom(nil, digits, settings.onProgress)},
+		exponent: exp,
+		source:   source,
+	}
+	return opaqueNumber(result)
+}
+
+type rootSettings struct {
+	onProgress func(digitsComputed int)
+}
+
+type rootOptionFunc func(s *rootSettings)
+
+func (o rootOptionFunc) mutateRoot(s *rootSettings) {
+	o(s)
 }
 
 // newNumber returns a new number. The first digit that digits generates
@@ -389,6 +1254,58 @@ func newFiniteNumber(digits func() int, exp int) *FiniteNumber {
 	return &FiniteNumber{exponent: exp, mantissa: mantissa}
 }
 
+// newStaticFiniteNumber computes all the digits up front. digits must
+// eventually return -1.
+func newStaticFiniteNumber(digits func() int, exp int) *FiniteNumber {
+	mantissa := mantissa{spec: newStaticSpec(digits)}
+	return &FiniteNumber{exponent: exp, mantissa: mantissa}
+}
+
+func compareFinite(a, b *FiniteNumber) int {
+	switch {
+	case a.IsZero() && b.IsZero():
+		return 0
+	case a.IsZero():
+		return -1
+	case b.IsZero():
+		return 1
+	}
+	if c := cmp.Compare(a.exponent, b.exponent); c != 0 {
+		return c
+	}
+	aDigits, bDigits := a.mantissa.allDigits(), b.mantissa.allDigits()
+	for i := range max(len(aDigits), len(bDigits)) {
+		if c := cmp.Compare(digitAt(aDigits, i), digitAt(bDigits, i)); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+func digitAt(digits []int8, posit int) int8 {
+	if posit >= len(digits) {
+		return 0
+	}
+	return digits[posit]
+}
+
+// tryNRootFrac works like nRootFrac except that it returns an error
+// instead of panicking if num or denom are out of range. name is the name
+// of the calling function which goes at the start of any error.
+func tryNRootFrac(
+	name string,
+	num, denom *big.Int,
+	newManager func() rootManager,
+	options ...RootOption) (Number, error) {
+	if denom.Sign() <= 0 {
+		return nil, fmt.Errorf("%s: denominator must be positive", name)
+	}
+	if num.Sign() < 0 {
+		return nil, fmt.Errorf("%s: radican must be non-negative", name)
+	}
+	return nRootFrac(num, denom, newManager, options...), nil
+}
+
 func checkNumDenom(num, denom *big.Int) {
 	if denom.Sign() <= 0 {
 		panic("Denominator must be positive")
@@ -461,10 +1378,29 @@ func (m mantissa) ScanValues(index int, yield func(value int) bool) {
 	m.spec.ScanValues(index, math.MaxInt, yield)
 }
 
+func (m mantissa) Known() (count int, complete bool) {
+	if m.spec == nil {
+		return 0, true
+	}
+	return m.spec.Known()
+}
+
+// IsExact returns true if m is not a truncation of more digits. m must not
+// have an infinite number of digits unless it is limited.
+func (m mantissa) IsExact() bool {
+	if ls, ok := m.spec.(*limitSpec); ok {
+		return ls.delegate.At(ls.limit) == -1
+	}
+	return true
+}
+
 func (m mantissa) WithLimit(limit int) mantissa {
 	return mantissa{spec: withLimit(m.spec, limit)}
 }
 
+// allDigits returns all the digits in m. The returned slice shares memory
+// with m's spec rather than being a copy, so callers must not modify it.
+// If m has a limit, only the digits up to that limit get computed.
 func (m mantissa) allDigits() []int8 {
 	if m.spec == nil {
 		return nil
@@ -482,6 +1418,12 @@ type formatSpec struct {
 func newFormatSpec(state fmt.State, verb rune, exponent int) (
 	formatSpec, bool) {
 	precision, precisionOk := state.Precision()
+	return formatSpecForVerb(verb, precision, precisionOk, exponent)
+}
+
+func formatSpecForVerb(
+	verb rune, precision int, precisionOk bool, exponent int) (
+	formatSpec, bool) {
 	switch verb {
 	case 'f', 'F':
 		if !precisionOk {
@@ -647,6 +1589,30 @@ func (n *opqNumber) WithStart(start int) Sequence {
 	return opaqueSequence(result)
 }
 
+func (n *opqNumber) Tail(start int) Number {
+	result := n.Number.Tail(start)
+	if result == n.Number {
+		return n
+	}
+	return opaqueNumber(result)
+}
+
+func (n *opqNumber) IsExact() bool {
+	_, complete := n.DigitsKnown()
+	return complete
+}
+
+func (n *opqNumber) ExactRat() (value *big.Rat, ok bool) {
+	if !n.IsExact() {
+		return nil, false
+	}
+	return n.Number.ExactRat()
+}
+
+func (n *opqNumber) WithMantissaExponent(exp int) Number {
+	return n.withExponent(exp)
+}
+
 func (n *opqNumber) withExponent(e int) Number {
 	result := n.Number.withExponent(e)
 	if result == n.Number {
@@ -5,6 +5,11 @@ import (
 	"iter"
 	"math"
 	"math/big"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -91,6 +96,20 @@ func TestReverse(t *testing.T) {
 	assert.Equal(t, []int{9, 7, 6, 0, 6, 3, 2, 2}, collect(iterator, 0))
 }
 
+func TestReverseNoCopy(t *testing.T) {
+	s := Sqrt(2).WithEnd(100000)
+	forward := collect(s.All(), 0)
+	assert.Len(t, forward, 100000)
+	slices.Reverse(forward)
+	assert.Equal(t, forward, collect(s.Backward(), 0))
+	assert.Equal(t, forward, exhaust(s.Reverse(), 0))
+	allocs := testing.AllocsPerRun(10, func() {
+		for range s.Backward() {
+		}
+	})
+	assert.Less(t, allocs, 10.0)
+}
+
 func TestIteratorAt(t *testing.T) {
 	n := Sqrt(100489)
 	assert.Empty(t, exhaust(n.WithStart(3).Iterator(), 0))
@@ -174,6 +193,173 @@ func TestSquareRootString(t *testing.T) {
 	assert.Equal(t, "3.162277660168379", number.String())
 }
 
+func TestSqrtImaginary(t *testing.T) {
+	magnitude, imaginary := SqrtImaginary(-2)
+	assert.True(t, imaginary)
+	assert.Equal(t, Sqrt(2).String(), magnitude.String())
+	assert.Zero(t, CompareWithin(1000)(Sqrt(2), magnitude))
+	magnitude, imaginary = SqrtImaginary(-100489)
+	assert.True(t, imaginary)
+	assert.Equal(t, "317", magnitude.String())
+	magnitude, imaginary = SqrtImaginary(math.MinInt64)
+	assert.True(t, imaginary)
+	assert.Equal(t, "3037000499.976049", fmt.Sprintf("%.6f", magnitude))
+}
+
+func TestSqrtImaginaryNonNegative(t *testing.T) {
+	magnitude, imaginary := SqrtImaginary(2)
+	assert.False(t, imaginary)
+	assert.Zero(t, CompareWithin(1000)(Sqrt(2), magnitude))
+	magnitude, imaginary = SqrtImaginary(0)
+	assert.False(t, imaginary)
+	assert.True(t, magnitude.IsZero())
+}
+
+func TestAGM(t *testing.T) {
+	agm12 := "14567910310469068691864323832650819749738639432213" +
+		"05590794172383267926454580250900257473712818448444"
+	n := AGM(1, 2, 10)
+	assert.Equal(t, 1, n.Exponent())
+	assert.True(t, n.HasPrefix(agm12))
+	assert.True(t, AGM(2, 1, 10).HasPrefix(agm12))
+	assert.Equal(t, "1.5", AGM(1, 2, 1).String())
+	assert.Equal(t, "1.457106781186547", AGM(1, 2, 2).String())
+}
+
+func TestAGMConverges(t *testing.T) {
+	exact := AGM(1, 2, 12).WithSignificant(100)
+	previous := 0
+	for iterations := 1; iterations < 8; iterations++ {
+		n := AGM(1, 2, iterations).WithSignificant(100)
+		agreement := 0
+		for agreement < 100 && n.At(agreement) == exact.At(agreement) {
+			agreement++
+		}
+		assert.Greater(t, agreement, previous)
+		previous = agreement
+	}
+	assert.Equal(t, 100, previous)
+}
+
+func TestAGMExact(t *testing.T) {
+	assert.Equal(t, "7", AGM(7, 7, 5).String())
+	assert.Equal(t, "5", AGM(5, 20, 0).String())
+	assert.Equal(t, "12.5", AGM(5, 20, 1).String())
+	assert.Equal(t, "0.625", AGM(0, 5, 3).String())
+	assert.True(t, AGM(0, 0, 3).IsZero())
+	assert.True(t, AGM(0, 5, 0).IsZero())
+	assert.Equal(t, -1, AGM(5, 20, 1).At(3))
+}
+
+func TestAGMPanics(t *testing.T) {
+	assert.Panics(t, func() { AGM(-1, 2, 3) })
+	assert.Panics(t, func() { AGM(1, -2, 3) })
+	assert.Panics(t, func() { AGM(1, 2, -3) })
+}
+
+func TestGeometricMean(t *testing.T) {
+	n := GeometricMean(2, 8)
+	assert.Equal(t, "4", n.String())
+	assert.Equal(t, 1, n.Exponent())
+	assert.Equal(t, -1, n.At(1))
+	assert.Equal(t, "2.449489742783178", GeometricMean(2, 3).String())
+	assert.True(t, GeometricMean(0, 8).IsZero())
+}
+
+func TestGeometricMeanBig(t *testing.T) {
+	n := GeometricMean(math.MaxInt64, math.MaxInt64)
+	assert.Equal(t, "9223372036854775807", fmt.Sprintf("%.0f", n))
+	assert.Equal(t, -1, n.At(19))
+	product := new(big.Int).Mul(
+		big.NewInt(math.MaxInt64), big.NewInt(math.MaxInt64-2))
+	assert.Equal(
+		t,
+		fmt.Sprintf("%.200g", SqrtBigInt(product)),
+		fmt.Sprintf("%.200g", GeometricMean(math.MaxInt64, math.MaxInt64-2)))
+}
+
+func TestGeometricMeanNegative(t *testing.T) {
+	assert.Panics(t, func() { GeometricMean(-2, -8) })
+	assert.Panics(t, func() { GeometricMean(2, -8) })
+}
+
+func TestQuadraticMean(t *testing.T) {
+	assert.Equal(
+		t,
+		fmt.Sprintf("%.500g", SqrtRat(25, 2)),
+		fmt.Sprintf("%.500g", QuadraticMean([]int64{3, 4})))
+	assert.Equal(t, "5", QuadraticMean([]int64{-5, 5, 5, -5}).String())
+	assert.Equal(t, "2", QuadraticMean([]int64{2}).String())
+	assert.True(t, QuadraticMean(nil).IsZero())
+	assert.True(t, QuadraticMean([]int64{0, 0}).IsZero())
+}
+
+func TestQuadraticMeanBig(t *testing.T) {
+	n := QuadraticMean([]int64{math.MaxInt64, math.MaxInt64})
+	assert.Equal(t, "9223372036854775807", fmt.Sprintf("%.0f", n))
+	assert.Equal(t, -1, n.At(19))
+}
+
+func TestHypot(t *testing.T) {
+	n := Hypot(3, 4)
+	assert.Equal(t, "5", n.String())
+	assert.Equal(t, -1, n.At(1))
+	assert.Equal(t, "13", Hypot(-5, 12).String())
+	assert.Equal(t, "13", Hypot(12, -5).String())
+	assert.Equal(
+		t,
+		fmt.Sprintf("%.500g", Sqrt(2)),
+		fmt.Sprintf("%.500g", Hypot(1, 1)))
+	assert.Equal(t, "7", Hypot(0, -7).String())
+	assert.True(t, Hypot(0, 0).IsZero())
+}
+
+func TestHypotBig(t *testing.T) {
+	sum := new(big.Int).Mul(
+		big.NewInt(math.MaxInt64), big.NewInt(math.MaxInt64))
+	sum.Lsh(sum, 1)
+	assert.Equal(
+		t,
+		fmt.Sprintf("%.200g", SqrtBigInt(sum)),
+		fmt.Sprintf("%.200g", Hypot(math.MaxInt64, math.MaxInt64)))
+	n := Hypot(math.MinInt64, 0)
+	assert.Equal(t, "9223372036854775808", fmt.Sprintf("%.0f", n))
+	assert.Equal(t, -1, n.At(19))
+}
+
+func TestSqrtCache(t *testing.T) {
+	cache := Cached()
+	n := cache.Sqrt(2)
+	assert.Same(t, n, cache.Sqrt(2))
+	assert.NotSame(t, n, cache.Sqrt(3))
+	assert.NotSame(t, n, Cached().Sqrt(2))
+	n.At(999)
+	count, _ := cache.Sqrt(2).DigitsKnown()
+	assert.GreaterOrEqual(t, count, 1000)
+	assert.Equal(t, fmt.Sprintf("%.1000g", Sqrt(2)), fmt.Sprintf("%.1000g", n))
+	assert.Equal(t, "317", cache.Sqrt(100489).String())
+	assert.True(t, cache.Sqrt(0).IsZero())
+	assert.Panics(t, func() { cache.Sqrt(-1) })
+}
+
+func TestSqrtCacheConcurrent(t *testing.T) {
+	cache := Cached()
+	var results [10]Number
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func(index int) {
+			defer wg.Done()
+			results[index] = cache.Sqrt(7)
+			results[index].At(500)
+		}(i)
+	}
+	wg.Wait()
+	for _, result := range results {
+		assert.Same(t, results[0], result)
+	}
+}
+
 func TestCubeRoot2(t *testing.T) {
 	assert.Equal(t, "1.25992104989487", fmt.Sprintf("%.15g", CubeRoot(2)))
 }
@@ -225,6 +411,70 @@ func TestExactShort(t *testing.T) {
 	assert.Equal(t, "0.00050", smallN.WithSignificant(2).Exact())
 }
 
+func TestExactParts(t *testing.T) {
+	n, _ := NewNumberForTesting([]int{5, 0, 0, 1}, nil, 3)
+	assertExactParts(t, n.WithSignificant(20), "500", "1", 0)
+	assertExactParts(t, n.WithSignificant(3), "500", "", 0)
+	assertExactParts(t, n.WithSignificant(1), "500", "", 0)
+	assertExactParts(t, n.WithSignificant(0), "0", "", 0)
+	smallN := n.withExponent(-3)
+	assertExactParts(t, smallN.WithSignificant(4), "0", "0005001", 0)
+	assertExactParts(t, smallN.WithSignificant(2), "0", "00050", 0)
+	assertExactParts(t, n.withExponent(-4).WithSignificant(4), "0", "5001", -4)
+	assertExactParts(t, n.withExponent(9).WithSignificant(4), "0", "5001", 9)
+	var zero FiniteNumber
+	assertExactParts(t, &zero, "0", "", 0)
+}
+
+func assertExactParts(
+	t *testing.T, n *FiniteNumber, intPart, fracPart string, exp int) {
+	t.Helper()
+	actualInt, actualFrac, actualExp := n.ExactParts()
+	assert.Equal(t, intPart, actualInt)
+	assert.Equal(t, fracPart, actualFrac)
+	assert.Equal(t, exp, actualExp)
+}
+
+func TestReversed(t *testing.T) {
+	n := Sqrt(2).WithSignificant(1000)
+	reversed := n.Reversed()
+	assert.Equal(t, n.Exponent(), reversed.Exponent())
+	assert.Equal(t, exhaust(n.Reverse(), 0), exhaust(reversed.Iterator(), 0))
+	assert.Equal(t, DigitsToString(n), DigitsToString(reversed.Reversed()))
+	small, _ := NewFiniteNumber([]int{3, 1, 7}, 3)
+	assert.Equal(t, "713", small.Reversed().String())
+	assert.Equal(t, "317", small.Reversed().Reversed().String())
+}
+
+func TestReversedTrailingZeros(t *testing.T) {
+	n, _ := NewFiniteNumber([]int{1, 2, 0}, 0)
+	assert.Equal(t, "0.021", n.Reversed().Exact())
+	assert.Equal(t, "0.012", n.Reversed().Reversed().Exact())
+}
+
+func TestReversedZero(t *testing.T) {
+	var zero FiniteNumber
+	assert.Same(t, &zero, zero.Reversed())
+	assert.True(t, zeroNumber.Reversed().IsZero())
+}
+
+func TestTrailingZeros(t *testing.T) {
+	n, _ := NewFiniteNumber([]int{5, 0, 0, 1}, 3)
+	assert.Equal(t, 0, n.TrailingZeros())
+	n, _ = NewFiniteNumber([]int{1, 2, 0, 0}, 0)
+	assert.Equal(t, 2, n.TrailingZeros())
+	assert.Equal(t, 1, n.WithSignificant(3).TrailingZeros())
+	assert.Equal(t, 0, n.WithSignificant(2).TrailingZeros())
+	n, _ = NewFiniteNumber([]int{7, 0, 0, 0, 0, 0}, 6)
+	assert.Equal(t, 5, n.TrailingZeros())
+	assert.Equal(t, 0, n.Reversed().TrailingZeros())
+
+	// The 21st digit of the square root of 2 is 0.
+	assert.Equal(t, 1, Sqrt(2).WithSignificant(21).TrailingZeros())
+	var zero FiniteNumber
+	assert.Equal(t, 0, zero.TrailingZeros())
+}
+
 func TestExactZero(t *testing.T) {
 	var n FiniteNumber
 	assert.Equal(t, "0", n.Exact())
@@ -323,6 +573,264 @@ func TestNewNumber(t *testing.T) {
 	assert.Equal(t, "0.1211211121111211", n.String())
 }
 
+func TestLimitGenerator(t *testing.T) {
+	n := NewNumber(
+		LimitGenerator(&testgenerator{first: 1, second: 2, exp: 2}, 7))
+	assert.Equal(t, "12.11211", n.String())
+	assert.Equal(t, 2, n.Exponent())
+	assert.Equal(t, 1, n.At(6))
+	assert.Equal(t, -1, n.At(7))
+	assert.Equal(t, 7, endOf(n.WithSignificant(math.MaxInt)))
+}
+
+func TestLimitGeneratorZero(t *testing.T) {
+	n := NewNumber(LimitGenerator(&testgenerator{first: 1, second: 2}, 0))
+	assert.True(t, n.IsZero())
+}
+
+func TestNewNumberFromDigitFunc(t *testing.T) {
+
+	// n = 0.123456789101112...
+	var champernowne []byte
+	for i := 1; len(champernowne) < 1000; i++ {
+		champernowne = strconv.AppendInt(champernowne, int64(i), 10)
+	}
+	n, err := NewNumberFromDigitFunc(
+		func(i int) int { return int(champernowne[i] - '0') }, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "0.1234567891011121", n.String())
+	assert.Equal(t, 1, n.At(9))
+	assert.Equal(t, 0, n.At(10))
+	assert.Equal(t, 1, n.At(11))
+	assert.Equal(t, "100", DigitsToString(n.WithStart(189).WithEnd(192)))
+}
+
+func TestNewNumberFromDigitFuncFinite(t *testing.T) {
+	digits := []int{3, 1, 7}
+	n, err := NewNumberFromDigitFunc(func(i int) int {
+		if i >= len(digits) {
+			return -1
+		}
+		return digits[i]
+	}, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, "317", n.String())
+	assert.Equal(t, -1, n.At(3))
+}
+
+func TestNewNumberFromDigitFuncZero(t *testing.T) {
+	n, err := NewNumberFromDigitFunc(func(i int) int { return -1 }, 3)
+	assert.NoError(t, err)
+	assert.True(t, n.IsZero())
+}
+
+func TestNewNumberFromChannel(t *testing.T) {
+	ch := make(chan int)
+	go func() {
+		for value := range Sqrt(2).WithSignificant(1000).Values() {
+			ch <- value
+		}
+		close(ch)
+	}()
+	n := NewNumberFromChannel(ch, 1)
+	assert.Equal(t, "1.414213562373095", n.String())
+	assert.Equal(
+		t,
+		Sqrt(2).WithSignificant(1000).Exact(),
+		n.WithSignificant(2000).Exact())
+	assert.Equal(t, -1, n.At(1000))
+}
+
+func TestNewNumberFromChannelEnd(t *testing.T) {
+	ch := make(chan int, 10)
+	for _, value := range []int{3, 1, 7, -1, 5} {
+		ch <- value
+	}
+	n := NewNumberFromChannel(ch, -1)
+	assert.Equal(t, "0.0317", n.String())
+	assert.Equal(t, 5, <-ch)
+}
+
+func TestNewNumberFromChannelZero(t *testing.T) {
+	ch := make(chan int, 10)
+	ch <- 0
+	ch <- 5
+	assert.True(t, NewNumberFromChannel(ch, 0).IsZero())
+	ch = make(chan int)
+	close(ch)
+	assert.True(t, NewNumberFromChannel(ch, 0).IsZero())
+}
+
+func TestNewNumberFromDigitFuncErrors(t *testing.T) {
+	_, err := NewNumberFromDigitFunc(func(i int) int { return i }, 0)
+	assert.Error(t, err)
+	_, err = NewNumberFromDigitFunc(func(i int) int { return 10 }, 0)
+	assert.Error(t, err)
+}
+
+func TestTrySqrt(t *testing.T) {
+	n, err := TrySqrt(2)
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("%.1000f", Sqrt(2)), fmt.Sprintf("%.1000f", n))
+	n, err = TrySqrt(0)
+	assert.NoError(t, err)
+	assert.True(t, n.IsZero())
+	n, err = TrySqrtRat(5, 8)
+	assert.NoError(t, err)
+	assert.Equal(t, SqrtRat(5, 8).String(), n.String())
+	n, err = TrySqrtBigInt(big.NewInt(100489))
+	assert.NoError(t, err)
+	assert.Equal(t, "317", n.String())
+	n, err = TrySqrtBigRat(big.NewRat(1, 4))
+	assert.NoError(t, err)
+	assert.Equal(t, "0.5", n.String())
+}
+
+func TestTrySqrtErrors(t *testing.T) {
+	_, err := TrySqrt(-1)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "TrySqrt")
+	_, err = TrySqrtRat(-1, 2)
+	assert.Error(t, err)
+	_, err = TrySqrtRat(1, 0)
+	assert.Error(t, err)
+	_, err = TrySqrtRat(1, -2)
+	assert.Error(t, err)
+	_, err = TrySqrtBigInt(big.NewInt(-4))
+	assert.Error(t, err)
+	_, err = TrySqrtBigRat(big.NewRat(-1, 4))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "TrySqrtBigRat")
+}
+
+func TestSqrtBigFloat(t *testing.T) {
+	radicans := []*big.Rat{
+		big.NewRat(2, 1), big.NewRat(5, 8), big.NewRat(100489, 1)}
+	for _, radican := range radicans {
+		f := new(big.Float).SetRat(radican)
+		assert.Equal(
+			t,
+			fmt.Sprintf("%.500f", SqrtBigRat(radican)),
+			fmt.Sprintf("%.500f", SqrtBigFloat(f)))
+	}
+	f, _ := new(big.Float).SetPrec(200).SetString("0.1")
+	exact, _ := f.Rat(nil)
+	assert.Equal(
+		t,
+		fmt.Sprintf("%.300f", SqrtBigRat(exact)),
+		fmt.Sprintf("%.300f", SqrtBigFloat(f)))
+	assert.True(t, SqrtBigFloat(new(big.Float)).IsZero())
+}
+
+func TestSqrtBigFloatPanics(t *testing.T) {
+	assert.Panics(t, func() { SqrtBigFloat(big.NewFloat(-2)) })
+	assert.Panics(t, func() { SqrtBigFloat(big.NewFloat(math.Inf(1))) })
+}
+
+func TestIntSqrt(t *testing.T) {
+	for i := int64(0); i < 1000; i++ {
+		radican := big.NewInt(i)
+		expected := new(big.Int).Sqrt(radican)
+		assert.Equal(t, expected, IntSqrt(radican))
+		root, remainder := IntSqrtRem(radican)
+		assert.Equal(t, expected, root)
+		root.Mul(root, root)
+		assert.Equal(t, radican, root.Add(root, remainder))
+	}
+	huge, _ := new(big.Int).SetString("100000000000000000000000000000000", 10)
+	root, remainder := IntSqrtRem(huge)
+	assert.Equal(t, "10000000000000000", root.String())
+	assert.Zero(t, remainder.Sign())
+	huge.Sub(huge, one)
+	root, remainder = IntSqrtRem(huge)
+	assert.Equal(t, "9999999999999999", root.String())
+	assert.Equal(t, "19999999999999998", remainder.String())
+	assert.Equal(t, "99999999999999999999999999999999", huge.String())
+}
+
+func TestIntSqrtPanics(t *testing.T) {
+	assert.Panics(t, func() { IntSqrt(big.NewInt(-1)) })
+	assert.Panics(t, func() { IntSqrtRem(big.NewInt(-4)) })
+}
+
+func TestSqrtToError(t *testing.T) {
+	assert.Equal(t, "1.414", SqrtToError(2, big.NewRat(1, 1000)).String())
+	assert.Equal(t, "1.4142", SqrtToError(2, big.NewRat(9, 10000)).String())
+	assert.Equal(t, "1", SqrtToError(2, big.NewRat(2, 1)).String())
+	assert.Equal(t, "317", SqrtToError(100489, big.NewRat(1, 1000000)).String())
+	lastCount := 0
+	for _, relErr := range []*big.Rat{
+		big.NewRat(1, 3),
+		big.NewRat(1, 70),
+		big.NewRat(3, 100000),
+		big.NewRat(1, 1000000000000),
+	} {
+		for _, radican := range []int64{2, 3, 99, 1000003} {
+			n := SqrtToError(radican, relErr)
+			count := len(DigitsToString(n))
+			if radican == 2 {
+				assert.Greater(t, count, lastCount)
+				lastCount = count
+			}
+			precise := truncatedRat(Sqrt(radican), count+50)
+			diff := new(big.Rat).Sub(precise, truncatedRat(n, count))
+			diff.Quo(diff, precise)
+			assert.Negative(t, diff.Cmp(relErr))
+		}
+	}
+}
+
+func TestSqrtToErrorPanics(t *testing.T) {
+	assert.Panics(t, func() { SqrtToError(2, new(big.Rat)) })
+	assert.Panics(t, func() { SqrtToError(2, big.NewRat(-1, 10)) })
+	assert.Panics(t, func() { SqrtToError(-2, big.NewRat(1, 10)) })
+}
+
+func TestSqrtAll(t *testing.T) {
+	radicans := []int64{2, 3, 100489, 0, -4, 1000003, 5}
+	roots := SqrtAll(radicans, 200)
+	assert.Len(t, roots, len(radicans))
+	for i, radican := range radicans {
+		if radican < 0 {
+			assert.True(t, roots[i].IsZero())
+			continue
+		}
+		expected := Sqrt(radican).WithSignificant(200)
+		assert.Equal(t, expected.Exact(), roots[i].Exact())
+		_, complete := roots[i].DigitsKnown()
+		assert.True(t, complete)
+	}
+	assert.Empty(t, SqrtAll(nil, 200))
+	assert.Panics(t, func() { SqrtAll(radicans, -1) })
+}
+
+func TestMapGenerator(t *testing.T) {
+	sqrt2 := newNRootGenerator(big.NewInt(2), one, newSqrtManager)
+	n := NewNumber(MapGenerator(sqrt2, func(d int) int { return 9 - d }))
+
+	// sqrt(2) = 1.4142135623730950488...
+	assert.Equal(t, "8.585786437626904951", fmt.Sprintf("%.19g", n))
+	assert.Equal(t, 1, n.Exponent())
+}
+
+func TestMapGeneratorEnd(t *testing.T) {
+	gen := MapGenerator(
+		&testgenerator{first: 1, second: 2, exp: 2},
+		func(d int) int {
+			if d == 2 {
+				return 10
+			}
+			return 7
+		})
+	n := NewNumber(gen)
+	assert.Equal(t, "70", n.String())
+	assert.True(t, n.IsExact())
+	n = NewNumber(MapGenerator(
+		LimitGenerator(&testgenerator{first: 1, second: 2}, 4),
+		func(d int) int { return d + 3 }))
+	assert.Equal(t, "0.4544", n.String())
+}
+
 func TestNewNumberIllegal(t *testing.T) {
 	n := NewNumber(&testgenerator{first: 5, second: 10})
 	assert.Equal(t, "0.5", n.String())
@@ -374,6 +882,594 @@ func TestNegDenom(t *testing.T) {
 	assert.Panics(t, func() { SqrtBigRat(radican) })
 }
 
+func TestCompareWithin(t *testing.T) {
+	sqrt2, sqrt3, sqrt5 := Sqrt(2), Sqrt(3), Sqrt(5)
+	numbers := []Number{sqrt3, sqrt2, sqrt5}
+	slices.SortFunc(numbers, CompareWithin(100))
+	assert.Same(t, sqrt2, numbers[0])
+	assert.Same(t, sqrt3, numbers[1])
+	assert.Same(t, sqrt5, numbers[2])
+}
+
+func TestCompareWithinPrecision(t *testing.T) {
+	a := SqrtRat(1000001, 100)
+	b := Sqrt(10000)
+	assert.Equal(t, 0, CompareWithin(6)(a, b))
+	assert.Equal(t, 1, CompareWithin(8)(a, b))
+	assert.Equal(t, -1, CompareWithin(8)(b, a))
+	assert.Equal(t, 0, CompareWithin(0)(a, b))
+}
+
+func TestCompareWithinExponentAndZero(t *testing.T) {
+	compare := CompareWithin(10)
+	assert.Equal(t, -1, compare(Sqrt(99), Sqrt(100)))
+	assert.Equal(t, 1, compare(Sqrt(100), Sqrt(99)))
+	assert.Equal(t, -1, compare(zeroNumber, Sqrt(2)))
+	assert.Equal(t, 1, compare(SqrtRat(1, 100), zeroNumber))
+	assert.Equal(t, 0, compare(zeroNumber, Sqrt(0)))
+	assert.Equal(t, 0, compare(Sqrt(2), Sqrt(2)))
+}
+
+func TestCompareWithinTrailingZeros(t *testing.T) {
+	a, _ := NewNumberForTesting([]int{1, 0, 0}, nil, 0)
+	b, _ := NewNumberForTesting([]int{1}, nil, 0)
+	assert.Equal(t, 0, CompareWithin(5)(a, b))
+}
+
+func TestCompareWithinPanics(t *testing.T) {
+	assert.Panics(t, func() { CompareWithin(-1) })
+}
+
+func TestPerfectPowers(t *testing.T) {
+	goroutines := runtime.NumGoroutine()
+	n := Sqrt(100489)
+	assert.IsType(t, (*opqNumber)(nil), n)
+	assert.IsType(t, (*staticSpec)(nil), rootSpec(n))
+	assert.Equal(t, "317", n.String())
+	assert.Equal(t, 3, n.Exponent())
+	n = CubeRoot(35223040952)
+	assert.IsType(t, (*staticSpec)(nil), rootSpec(n))
+	assert.Equal(t, "3278", n.String())
+	assert.Equal(t, 4, n.Exponent())
+	n = SqrtRat(1, 4)
+	assert.IsType(t, (*staticSpec)(nil), rootSpec(n))
+	assert.Equal(t, "0.5", n.String())
+	n = CubeRootRat(27, 1000000)
+	assert.IsType(t, (*staticSpec)(nil), rootSpec(n))
+	assert.Equal(t, "0.03", n.String())
+	n = GeometricMean(4, 9)
+	assert.IsType(t, (*staticSpec)(nil), rootSpec(n))
+	assert.Equal(t, "6", n.String())
+	assert.Equal(t, goroutines, runtime.NumGoroutine())
+}
+
+func TestNotPerfectPowers(t *testing.T) {
+	for _, n := range []Number{
+		Sqrt(100490),
+		CubeRoot(35223040953),
+		SqrtRat(1, 9),
+		CubeRootRat(8, 27),
+		CubeRoot(100),
+	} {
+		assert.IsType(t, (*opqNumber)(nil), n)
+		_, ok := rootSpec(n).(*staticSpec)
+		assert.False(t, ok)
+	}
+}
+
+// rootSpec returns the numberSpec behind a Number that a root function
+// returned.
+func rootSpec(n Number) numberSpec {
+	return n.(*opqNumber).Number.(*FiniteNumber).mantissa.spec
+}
+
+func TestIntRoot(t *testing.T) {
+	for _, degree := range []int{2, 3, 4} {
+		for x := int64(1); x < 2000; x++ {
+			root, exact := intRoot(big.NewInt(x), degree)
+			r := root.Int64()
+			assert.LessOrEqual(t, int64(math.Pow(float64(r), float64(degree))), x)
+			assert.Greater(
+				t, int64(math.Pow(float64(r+1), float64(degree))), x)
+			assert.Equal(
+				t, exact, int64(math.Pow(float64(r), float64(degree))) == x)
+		}
+	}
+}
+
+func TestDigitsForPrecision(t *testing.T) {
+	assert.Equal(t, 51, DigitsForPrecision(Sqrt(2), 50))
+	assert.Equal(t, 53, DigitsForPrecision(Sqrt(50176), 50))
+	assert.Equal(t, 49, DigitsForPrecision(SqrtRat(2600, 1000000), 50))
+	assert.Equal(t, 50, DigitsForPrecision(SqrtRat(26, 1000), 50))
+	assert.Equal(t, 0, DigitsForPrecision(SqrtRat(2600, 1000000), 0))
+	assert.Equal(t, 0, DigitsForPrecision(SqrtRat(2600, 1000000), 1))
+	assert.Equal(t, 2, DigitsForPrecision(Sqrt(50176), -1))
+	assert.Equal(t, 0, DigitsForPrecision(Sqrt(50176), -5))
+}
+
+func TestDigitsForPrecisionMatchesF(t *testing.T) {
+	numbers := []Number{
+		Sqrt(2), Sqrt(1234567), SqrtRat(2600, 1000000),
+		SqrtRat(26, 1000), CubeRootRat(2, 73952)}
+	for _, n := range numbers {
+		for _, places := range []int{2, 10, 30} {
+			digits := DigitsForPrecision(n, places)
+			truncated := n.WithSignificant(digits)
+			assert.Equal(
+				t,
+				fmt.Sprintf("%.*f", places, n),
+				fmt.Sprintf("%.*f", places, truncated))
+			assert.Equal(t, -1, truncated.At(digits))
+		}
+	}
+}
+
+func TestPointOffset(t *testing.T) {
+	assert.Equal(t, 1, Sqrt(2).PointOffset(5))
+	assert.Equal(t, 3, Sqrt(50176).PointOffset(5))
+	assert.Equal(t, -1, Sqrt(50176).PointOffset(3))
+	assert.Equal(t, 1, SqrtRat(2600, 1000000).PointOffset(5))
+	assert.Equal(t, 1, zeroNumber.PointOffset(5))
+	assert.Equal(t, -1, zeroNumber.PointOffset(0))
+}
+
+func TestPointOffsetMatchesF(t *testing.T) {
+	numbers := []Number{
+		Sqrt(2), Sqrt(50176), Sqrt(1234567), SqrtRat(2600, 1000000),
+		SqrtRat(26, 1000), CubeRoot(35223040952), zeroNumber}
+	for _, n := range numbers {
+		for precision := 0; precision < 5; precision++ {
+			fixed := fmt.Sprintf("%.*f", precision, n)
+			assert.Equal(
+				t,
+				strings.IndexByte(fixed, '.'),
+				n.PointOffset(precision+n.Exponent()),
+				fixed)
+		}
+	}
+}
+
+func TestAppendFormat(t *testing.T) {
+	numbers := []Number{
+		Sqrt(2), Sqrt(10000000000), SqrtRat(1, 1000000), zeroNumber}
+	for _, n := range numbers {
+		for _, verb := range []byte("fFgGeE") {
+			for _, precision := range []int{0, 1, 5, 20} {
+				format := fmt.Sprintf("%%.%d%c", precision, verb)
+				expected := fmt.Sprintf(format, n)
+				actual := n.AppendFormat([]byte("x="), verb, precision)
+				assert.Equal(t, "x="+expected, string(actual))
+			}
+			expected := fmt.Sprintf("%"+string(verb), n)
+			assert.Equal(t, expected, string(n.AppendFormat(nil, verb, -1)))
+		}
+	}
+}
+
+func TestAppendFormatBadVerb(t *testing.T) {
+	actual := Sqrt(2).AppendFormat([]byte("x="), 'd', 3)
+	assert.Equal(t, "x=%d", string(actual))
+}
+
+func TestNumberSqrt(t *testing.T) {
+	fourthRoot2 := "11892071150027210667174999705604759152929720924638" +
+		"17413019002224719466668226917159870781344538137673" +
+		"71603739477476921318606372636178984775678536086253" +
+		"80177750701515114035570922731623428688899241754460"
+	n := Sqrt(2).Sqrt()
+	assert.Equal(t, 1, n.Exponent())
+	assert.True(t, n.HasPrefix(fourthRoot2))
+}
+
+func TestNumberSqrtOddExponent(t *testing.T) {
+	fourthRoot10 := "17782794100389228012254211951926848447357905264022" +
+		"55358011830722776301881539493804900300399278702155"
+	n := Sqrt(10).Sqrt()
+	assert.Equal(t, 1, n.Exponent())
+	assert.True(t, n.HasPrefix(fourthRoot10))
+}
+
+func TestNumberSqrtSmall(t *testing.T) {
+	n := SqrtRat(1, 1000000).Sqrt()
+	expected := SqrtRat(1, 1000)
+	assert.Equal(t, expected.Exponent(), n.Exponent())
+	assert.Zero(t, CompareWithin(300)(expected, n))
+}
+
+func TestNumberSqrtFinite(t *testing.T) {
+	n, err := NewFiniteNumber([]int{1, 6}, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, "4", n.Sqrt().String())
+	n, err = NewFiniteNumber([]int{1, 6}, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "0.4", n.Sqrt().String())
+	n, err = NewFiniteNumber([]int{1, 0, 0, 4, 8, 9}, 6)
+	assert.NoError(t, err)
+	root := n.Sqrt()
+	assert.Equal(t, "317", root.String())
+	assert.Equal(t, -1, root.At(3))
+}
+
+func TestNumberSqrtZero(t *testing.T) {
+	assert.Same(t, zeroNumber, zeroNumber.Sqrt())
+}
+
+func TestDigitsKnown(t *testing.T) {
+	n, _ := NewFiniteNumber([]int{3, 1, 7}, 3)
+	count, complete := n.DigitsKnown()
+	assert.Zero(t, count)
+	assert.False(t, complete)
+	assert.Equal(t, -1, n.At(3))
+	count, complete = n.DigitsKnown()
+	assert.Equal(t, 3, count)
+	assert.True(t, complete)
+
+	// Perfect squares have all their digits computed up front.
+	count, complete = Sqrt(100489).DigitsKnown()
+	assert.Equal(t, 3, count)
+	assert.True(t, complete)
+}
+
+func TestDigitsKnownInfinite(t *testing.T) {
+	n := Sqrt(2)
+	count, complete := n.DigitsKnown()
+	assert.Zero(t, count)
+	assert.False(t, complete)
+	n.At(150)
+	count, complete = n.DigitsKnown()
+	assert.GreaterOrEqual(t, count, 151)
+	assert.False(t, complete)
+	count, complete = n.WithSignificant(100).DigitsKnown()
+	assert.Equal(t, 100, count)
+	assert.True(t, complete)
+	count, complete = n.WithSignificant(1000).DigitsKnown()
+	assert.Less(t, count, 1000)
+	assert.False(t, complete)
+}
+
+func TestIsExact(t *testing.T) {
+	assert.True(t, Sqrt(100489).IsExact())
+	assert.True(t, Sqrt(100489).WithSignificant(3).IsExact())
+	assert.True(t, Sqrt(100489).WithSignificant(100).IsExact())
+	assert.False(t, Sqrt(100489).WithSignificant(2).IsExact())
+	assert.True(t, SqrtRat(1, 4).IsExact())
+	assert.True(t, CubeRootRat(1, 8).IsExact())
+	assert.False(t, Sqrt(2).IsExact())
+	assert.False(t, Sqrt(2).WithSignificant(10).IsExact())
+	assert.False(t, Sqrt(2).WithSignificant(10).withExponent(5).IsExact())
+	n, _ := NewFiniteNumber([]int{3, 1, 7}, 3)
+	assert.True(t, n.IsExact())
+	assert.False(t, n.WithSignificant(2).IsExact())
+	assert.True(t, zeroNumber.IsExact())
+	var zero FiniteNumber
+	assert.True(t, zero.IsExact())
+}
+
+func TestIsExactLazy(t *testing.T) {
+	n := NewNumber(
+		LimitGenerator(&testgenerator{first: 1, second: 2}, 7))
+	assert.False(t, n.IsExact())
+	assert.True(t, n.WithSignificant(7).IsExact())
+	assert.False(t, n.WithSignificant(6).IsExact())
+	assert.Equal(t, -1, n.At(7))
+	assert.True(t, n.IsExact())
+}
+
+func TestExactRatFresh(t *testing.T) {
+	n, _ := NewFiniteNumber([]int{3, 1, 7}, 3)
+	value, ok := n.ExactRat()
+	assert.True(t, ok)
+	assert.Equal(t, big.NewRat(317, 1), value)
+	n, _ = NewFiniteNumber([]int{3, 1, 0, 0, 7}, 3)
+	value, ok = n.withExponent(-1).ExactRat()
+	assert.True(t, ok)
+	assert.Equal(t, big.NewRat(31007, 1000000), value)
+}
+
+func TestExactRat(t *testing.T) {
+	value, ok := CubeRoot(8).ExactRat()
+	assert.True(t, ok)
+	assert.Equal(t, big.NewRat(2, 1), value)
+	value, ok = CubeRoot(35223040952).ExactRat()
+	assert.True(t, ok)
+	assert.Equal(t, big.NewRat(3278, 1), value)
+	value, ok = SqrtRat(1, 64).ExactRat()
+	assert.True(t, ok)
+	assert.Equal(t, big.NewRat(1, 8), value)
+	value, ok = Sqrt(100489).WithSignificant(100).withExponent(-1).ExactRat()
+	assert.True(t, ok)
+	assert.Equal(t, big.NewRat(317, 10000), value)
+	value, ok = zeroNumber.ExactRat()
+	assert.True(t, ok)
+	assert.Zero(t, value.Sign())
+	value, ok = Sqrt(2).ExactRat()
+	assert.False(t, ok)
+	assert.Nil(t, value)
+	_, ok = Sqrt(2).WithSignificant(10).ExactRat()
+	assert.False(t, ok)
+	_, ok = Sqrt(100489).WithSignificant(2).ExactRat()
+	assert.False(t, ok)
+}
+
+func TestCmpFloat64(t *testing.T) {
+	assert.Equal(t, -1, Sqrt(2).CmpFloat64(1.5))
+	assert.Equal(t, 1, Sqrt(3).CmpFloat64(1.5))
+
+	// The float64 closest to the square root of 2 is slightly too big.
+	assert.Equal(t, -1, Sqrt(2).CmpFloat64(math.Sqrt2))
+	assert.Equal(t, 1, Sqrt(2).CmpFloat64(math.Nextafter(math.Sqrt2, 1)))
+	assert.Equal(t, 0, SqrtRat(9, 4).CmpFloat64(1.5))
+	assert.Equal(t, 1, SqrtRat(9, 4).CmpFloat64(1.4999999999999998))
+	assert.Equal(t, -1, SqrtRat(9, 4).CmpFloat64(1.5000000000000002))
+	assert.Equal(t, 0, Sqrt(100489).CmpFloat64(317))
+	assert.Equal(t, -1, Sqrt(100489).WithSignificant(2).CmpFloat64(317))
+	assert.Equal(t, 1, SqrtRat(1, 10000).CmpFloat64(0.009999))
+	assert.Equal(t, -1, SqrtRat(1, 10000).CmpFloat64(0.01))
+	assert.Equal(t, -1, NewNumberFromBigRat(big.NewRat(1, 10)).CmpFloat64(0.1))
+	assert.Equal(t, 1, Sqrt(2).CmpFloat64(0))
+	assert.Equal(t, 1, Sqrt(2).CmpFloat64(-1.5))
+	assert.Equal(t, -1, Sqrt(2).CmpFloat64(math.Inf(1)))
+	assert.Equal(t, 1, Sqrt(2).CmpFloat64(math.Inf(-1)))
+	assert.Equal(t, 0, zeroNumber.CmpFloat64(0))
+	assert.Equal(t, 0, zeroNumber.CmpFloat64(math.Copysign(0, -1)))
+	assert.Equal(t, -1, zeroNumber.CmpFloat64(math.SmallestNonzeroFloat64))
+	assert.Equal(t, 1, zeroNumber.CmpFloat64(-2))
+	assert.Panics(t, func() { Sqrt(2).CmpFloat64(math.NaN()) })
+}
+
+func TestWithMantissaExponent(t *testing.T) {
+	n := Sqrt(2)
+	scaled := n.WithMantissaExponent(-3)
+	assert.Equal(t, -3, scaled.Exponent())
+	assert.Equal(t, 1, n.Exponent())
+	assert.Equal(t, "0.0001414213562373095", scaled.String())
+	for i := 0; i < 1000; i++ {
+		assert.Equal(t, n.At(i), scaled.At(i))
+	}
+	count, _ := n.DigitsKnown()
+	scaledCount, _ := scaled.DigitsKnown()
+	assert.Equal(t, count, scaledCount)
+	assert.Same(t, n, n.WithMantissaExponent(1))
+	assert.Equal(t, "1414.213562373095", n.WithMantissaExponent(4).String())
+	small, _ := NewFiniteNumber([]int{3, 1, 7}, 3)
+	assert.Equal(t, "0.317", small.WithMantissaExponent(0).String())
+	assert.True(t, zeroNumber.WithMantissaExponent(5).IsZero())
+	assert.Equal(t, 0, zeroNumber.WithMantissaExponent(5).Exponent())
+}
+
+func TestFirstSignificantOffset(t *testing.T) {
+	n := SqrtRat(2600, 1000000)
+	assert.Equal(t, "0.05099019513592784", n.String())
+	assert.Equal(t, 1, n.FirstSignificantOffset())
+	assert.Equal(t, 0, SqrtRat(1, 4).FirstSignificantOffset())
+	assert.Equal(t, 2, SqrtRat(1, 30000).FirstSignificantOffset())
+	assert.Equal(t, 4, SqrtRat(1, 10000000000).FirstSignificantOffset())
+	assert.Equal(t, 0, Sqrt(2).FirstSignificantOffset())
+	assert.Equal(t, 0, Sqrt(100489).FirstSignificantOffset())
+	assert.Equal(t, 0, zeroNumber.FirstSignificantOffset())
+}
+
+func TestLaTeX(t *testing.T) {
+	assert.Equal(t, `1.41421\ldots \times 10^{0}`, Sqrt(2).LaTeX(6))
+	assert.Equal(t, `1\ldots \times 10^{0}`, Sqrt(2).LaTeX(1))
+	assert.Equal(
+		t,
+		`1.4142\ldots \times 10^{0}`,
+		Sqrt(2).WithSignificant(5).LaTeX(10))
+	assert.Equal(t, `1.414\ldots \times 10^{3}`, Sqrt(2000000).LaTeX(4))
+	assert.Equal(
+		t, `5.099\ldots \times 10^{-2}`, SqrtRat(2600, 1000000).LaTeX(4))
+	assert.Equal(t, `3.1\ldots \times 10^{2}`, Sqrt(100489).LaTeX(2))
+	assert.Equal(
+		t, `1.414\ldots \times 10^{-50}`, Sqrt(2).withExponent(-49).LaTeX(4))
+	assert.Equal(
+		t, `1.414\ldots \times 10^{99}`, Sqrt(2).withExponent(100).LaTeX(4))
+}
+
+func TestLaTeXExact(t *testing.T) {
+	assert.Equal(t, "317", Sqrt(100489).LaTeX(3))
+	assert.Equal(t, "317", Sqrt(100489).LaTeX(6))
+	assert.Equal(t, "317", Sqrt(100489).LaTeX(100))
+	assert.Equal(t, "31.7", Sqrt(100489).withExponent(2).LaTeX(3))
+	assert.Equal(t, "0.5", SqrtRat(1, 4).LaTeX(10))
+	assert.Equal(t, "0.125", NewNumberFromBigRat(big.NewRat(1, 8)).LaTeX(5))
+	assert.Equal(t, "2", CubeRoot(8).LaTeX(3))
+	n, _ := NewFiniteNumber([]int{5, 0, 0, 1}, -4)
+	assert.Equal(t, "0.00005001", n.LaTeX(4))
+	n, _ = NewFiniteNumber([]int{5, 0, 0, 1}, 12)
+	assert.Equal(t, "500100000000", n.LaTeX(4))
+	assert.Equal(t, `5.00\ldots \times 10^{11}`, n.LaTeX(3))
+	assert.Equal(t, "0", zeroNumber.LaTeX(5))
+	assert.Panics(t, func() { Sqrt(2).LaTeX(0) })
+}
+
+func TestRoundToInt(t *testing.T) {
+	assert.Equal(t, big.NewInt(1), Sqrt(2).RoundToInt())
+	assert.Equal(t, big.NewInt(2), Sqrt(3).RoundToInt())
+	assert.Equal(t, big.NewInt(224), Sqrt(50176).RoundToInt())
+	assert.Equal(t, big.NewInt(317), Sqrt(100489).RoundToInt())
+	assert.Equal(t, big.NewInt(100), Sqrt(9999).RoundToInt())
+	assert.Equal(t, big.NewInt(1), SqrtRat(1, 3).RoundToInt())
+	assert.Equal(t, big.NewInt(0), SqrtRat(1, 5).RoundToInt())
+	assert.Equal(t, big.NewInt(0), SqrtRat(1, 30000).RoundToInt())
+	assert.Equal(t, big.NewInt(0), zeroNumber.RoundToInt())
+	expected, _ := new(big.Int).SetString("1414213562373095048802", 10)
+	assert.Equal(t, expected, Sqrt(2).withExponent(22).RoundToInt())
+}
+
+func TestRoundToIntHalfway(t *testing.T) {
+	n, _ := NewNumberForTesting([]int{2, 5}, nil, 1)
+	assert.Equal(t, big.NewInt(3), n.RoundToInt())
+	n, _ = NewNumberForTesting([]int{2, 4}, []int{9}, 1)
+	assert.Equal(t, big.NewInt(2), n.RoundToInt())
+	n, _ = NewNumberForTesting([]int{5}, nil, 0)
+	assert.Equal(t, big.NewInt(1), n.RoundToInt())
+	n, _ = NewNumberForTesting([]int{5}, nil, 4)
+	assert.Equal(t, big.NewInt(5000), n.RoundToInt())
+}
+
+func TestTail(t *testing.T) {
+
+	// sqrt(2) = 1.41421356237309504880...
+	n := Sqrt(2)
+	tail := n.Tail(3)
+	assert.Zero(t, tail.Exponent())
+	assert.Equal(t, 4, tail.At(0))
+	assert.Equal(t, "0.4213562373095048", tail.String())
+	assert.Equal(
+		t,
+		DigitsToString(n.WithStart(3).WithEnd(1000)),
+		DigitsToString(tail.WithEnd(997)))
+	_, ok := tail.(*FiniteNumber)
+	assert.False(t, ok)
+
+	// Skips the 0 at position 13.
+	tail = n.Tail(13)
+	assert.Equal(t, -1, tail.Exponent())
+	assert.Equal(t, 9, tail.At(0))
+	assert.Same(t, n, n.Tail(0))
+	assert.Same(t, n, n.Tail(-1))
+}
+
+func TestTailLeadingDigit(t *testing.T) {
+	n := Sqrt(2).withExponent(5)
+	for k := 1; k < 100; k++ {
+		if n.At(k) == 0 {
+			continue
+		}
+		tail := n.Tail(k)
+		assert.Equal(t, n.At(k), tail.At(0))
+		assert.Zero(t, tail.Exponent())
+	}
+}
+
+func TestTailFinite(t *testing.T) {
+	n, _ := NewFiniteNumber([]int{3, 1, 0, 0, 7}, 3)
+	tail := n.Tail(1)
+	assert.Equal(t, "0.1007", tail.String())
+	assert.IsType(t, &FiniteNumber{}, tail)
+	assert.Equal(t, "0.007", n.Tail(2).String())
+	assert.Equal(t, -2, n.Tail(2).Exponent())
+	assert.Equal(t, "0.7", n.Tail(4).String())
+	assert.True(t, n.Tail(5).IsZero())
+	assert.True(t, zeroNumber.Tail(3).IsZero())
+}
+
+func TestDigitsKnownZero(t *testing.T) {
+	count, complete := zeroNumber.DigitsKnown()
+	assert.Zero(t, count)
+	assert.True(t, complete)
+}
+
+func TestDigitsAsBigInt(t *testing.T) {
+	n := Sqrt(2)
+	assert.Equal(t, big.NewInt(14142), n.DigitsAsBigInt(5))
+	assert.Equal(t, big.NewInt(1), n.DigitsAsBigInt(1))
+	assert.Equal(t, big.NewInt(0), n.DigitsAsBigInt(0))
+	assert.Equal(t, big.NewInt(0), n.DigitsAsBigInt(-3))
+	expected, _ := new(big.Int).SetString(
+		"14142135623730950488016887242096980785696718753769", 10)
+	assert.Equal(t, expected, n.DigitsAsBigInt(50))
+	assert.Equal(
+		t, big.NewInt(10000004), SqrtRat(1000001, 100).DigitsAsBigInt(8))
+}
+
+func TestDigitsAsBigIntFinite(t *testing.T) {
+	n := Sqrt(100489)
+	assert.Equal(t, big.NewInt(317), n.DigitsAsBigInt(3))
+	assert.Equal(t, big.NewInt(317), n.DigitsAsBigInt(100))
+	assert.Equal(t, big.NewInt(31), n.DigitsAsBigInt(2))
+	assert.Equal(t, big.NewInt(0), zeroNumber.DigitsAsBigInt(10))
+}
+
+func TestCheckRoot(t *testing.T) {
+	assert.True(t, Sqrt(2).CheckRoot(big.NewRat(2, 1), 2, 100))
+	assert.True(t, CubeRoot(3).CheckRoot(big.NewRat(3, 1), 3, 100))
+	assert.True(t, SqrtRat(2, 3).CheckRoot(big.NewRat(2, 3), 2, 50))
+	assert.True(t, Sqrt(100489).CheckRoot(big.NewRat(100489, 1), 2, 10))
+	assert.False(t, Sqrt(2).CheckRoot(big.NewRat(3, 1), 2, 100))
+	assert.False(t, Sqrt(2).CheckRoot(big.NewRat(2, 1), 3, 100))
+	assert.False(t, Sqrt(100489).CheckRoot(big.NewRat(100490, 1), 2, 10))
+}
+
+func TestCheckRootWrongNumber(t *testing.T) {
+
+	// The square root of 2 with the 100th significant digit wrong
+	digits := slices.Collect(Sqrt(2).WithSignificant(100).Values())
+	digits[99] = (digits[99] + 1) % 10
+	n, _ := NewFiniteNumber(digits, 1)
+	assert.True(t, n.CheckRoot(big.NewRat(2, 1), 2, 99))
+	assert.False(t, n.CheckRoot(big.NewRat(2, 1), 2, 100))
+}
+
+func TestCheckRootPanics(t *testing.T) {
+	assert.Panics(t, func() { Sqrt(2).CheckRoot(big.NewRat(2, 1), 0, 10) })
+	assert.Panics(t, func() { Sqrt(2).CheckRoot(big.NewRat(2, 1), 2, -1) })
+}
+
+func TestMantissaExponent(t *testing.T) {
+	mantissa, exponent := Sqrt(2).MantissaExponent(5)
+	assert.Equal(t, "0.14142", mantissa)
+	assert.Equal(t, 1, exponent)
+	mantissa, exponent = Sqrt(100489).MantissaExponent(10)
+	assert.Equal(t, "0.317", mantissa)
+	assert.Equal(t, 3, exponent)
+	mantissa, exponent = zeroNumber.MantissaExponent(10)
+	assert.Equal(t, "0", mantissa)
+	assert.Zero(t, exponent)
+	mantissa, exponent = Sqrt(2).MantissaExponent(0)
+	assert.Equal(t, "0", mantissa)
+	assert.Zero(t, exponent)
+	assert.Panics(t, func() { Sqrt(2).MantissaExponent(-1) })
+}
+
+func TestMantissaExponentMatchesE(t *testing.T) {
+	numbers := []Number{
+		Sqrt(2), SqrtRat(1, 300000), Sqrt(123456789012), CubeRoot(5)}
+	for _, n := range numbers {
+		for _, sigDigits := range []int{1, 2, 8, 20} {
+			mantissa, exponent := n.MantissaExponent(sigDigits)
+			assert.Equal(
+				t,
+				fmt.Sprintf("%.*e", sigDigits, n),
+				fmt.Sprintf("%se%+03d", mantissa, exponent))
+		}
+	}
+}
+
+func TestHasPrefix(t *testing.T) {
+	n := Sqrt(2)
+	assert.True(t, n.HasPrefix("14142135"))
+	assert.True(t, n.HasPrefix(""))
+	assert.False(t, n.HasPrefix("14142136"))
+	assert.False(t, n.HasPrefix("24142135"))
+	assert.False(t, n.HasPrefix("1414.2135"))
+	assert.True(t, SqrtRat(2600, 1000000).HasPrefix("5099"))
+}
+
+func TestAgreementLength(t *testing.T) {
+	sqrt2 := "1.4142135623730950488016887242096980785696718753769"
+	n := Sqrt(2)
+	assert.Equal(t, 50, n.AgreementLength(sqrt2))
+	assert.Equal(t, 30, n.AgreementLength(sqrt2[:31]+"0"+sqrt2[32:]))
+	assert.Equal(t, 4, n.AgreementLength("1.41431"))
+	assert.Equal(t, 0, n.AgreementLength(""))
+	assert.Equal(t, 0, n.AgreementLength("2"))
+	assert.Equal(t, 3, Sqrt(100489).AgreementLength("317.0"))
+	assert.Equal(t, 4, SqrtRat(2600, 1000000).AgreementLength("0.0509911"))
+	assert.Equal(t, 0, zeroNumber.AgreementLength("0"))
+}
+
+func TestHasPrefixFinite(t *testing.T) {
+	n := Sqrt(100489)
+	assert.True(t, n.HasPrefix("317"))
+	assert.False(t, n.HasPrefix("3170"))
+	assert.False(t, n.HasPrefix("318"))
+	assert.True(t, zeroNumber.HasPrefix(""))
+	assert.False(t, zeroNumber.HasPrefix("0"))
+}
+
 func TestWithSignificant(t *testing.T) {
 	// Resolves to 6 significant digits
 	n := Sqrt(2).WithSignificant(9).WithSignificant(6).WithSignificant(10)
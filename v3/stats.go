@@ -0,0 +1,197 @@
+package sqroot
+
+import (
+	"math"
+	"math/big"
+	"slices"
+)
+
+// CountFunc returns the number of digits in s for which pred returns true.
+func CountFunc(s FiniteSequence, pred func(digit int) bool) int {
+	result := 0
+	for _, value := range s.All() {
+		if pred(value) {
+			result++
+		}
+	}
+	return result
+}
+
+// DistinctDigits returns how many of the ten digit values appear at least
+// once in s. DistinctDigits returns 0 if s is empty.
+func DistinctDigits(s FiniteSequence) int {
+	var seen [10]bool
+	result := 0
+	for value := range s.Values() {
+		if !seen[value] {
+			seen[value] = true
+			result++
+			if result == len(seen) {
+				break
+			}
+		}
+	}
+	return result
+}
+
+// MeanDigit returns the arithmetic mean of the digits in s, the sum of
+// the digits divided by how many there are, as a Number. Because the mean
+// is a Number, it holds the exact value of the fraction to as many digits
+// as needed. MeanDigit returns zero if s is empty.
+func MeanDigit(s FiniteSequence) Number {
+	var sum, count int64
+	for value := range s.Values() {
+		sum += int64(value)
+		count++
+	}
+	if count == 0 {
+		return zeroNumber
+	}
+	return NewNumberFromBigRat(big.NewRat(sum, count))
+}
+
+// ShannonEntropy returns the base 10 Shannon entropy of how often each
+// digit appears in s. The result ranges from 0 when s has only one distinct
+// digit up to 1 when all ten digits appear equally often, so values near 1
+// suggest that the digits of s are uniformly distributed. ShannonEntropy
+// returns 0 if s is empty.
+func ShannonEntropy(s FiniteSequence) float64 {
+	var counts [10]int
+	total := 0
+	for value := range s.Values() {
+		counts[value]++
+		total++
+	}
+	result := 0.0
+	for _, count := range counts {
+		if count > 0 {
+			p := float64(count) / float64(total)
+			result -= p * math.Log10(p)
+		}
+	}
+	return result
+}
+
+// ArgMax returns the position and value of the largest digit in s. If the
+// largest digit appears more than once, ArgMax returns the first
+// occurrence. If s is empty, ArgMax returns (-1, -1).
+func ArgMax(s FiniteSequence) (position, value int) {
+	return argBest(s, func(x, y int) bool { return x > y })
+}
+
+// ArgMin returns the position and value of the smallest digit in s. If the
+// smallest digit appears more than once, ArgMin returns the first
+// occurrence. If s is empty, ArgMin returns (-1, -1).
+func ArgMin(s FiniteSequence) (position, value int) {
+	return argBest(s, func(x, y int) bool { return x < y })
+}
+
+// NGramCounts returns how many times each run of k consecutive digits
+// appears in s. The keys of the returned map are the runs of digits as
+// strings. Runs may overlap. If s has fewer than k digits, NGramCounts
+// returns an empty map. Like the search functions, NGramCounts ignores
+// any gaps in the positions of s. NGramCounts panics if k is not positive.
+func NGramCounts(s FiniteSequence, k int) map[string]int {
+	if k <= 0 {
+		panic("k must be positive")
+	}
+	result := make(map[string]int)
+	window := make([]byte, 0, k)
+	for value := range s.Values() {
+		if len(window) == k {
+			window = append(window[:0], window[1:]...)
+		}
+		window = append(window, '0'+byte(value))
+		if len(window) == k {
+			result[string(window)]++
+		}
+	}
+	return result
+}
+
+// WindowLeadingDigitCounts splits the digits of s into consecutive,
+// non-overlapping windows of window digits each and returns how many
+// windows start with each digit value. If the last window has fewer than
+// window digits, WindowLeadingDigitCounts ignores it. Like NGramCounts,
+// WindowLeadingDigitCounts ignores any gaps in the positions of s.
+// WindowLeadingDigitCounts panics if window is not positive.
+func WindowLeadingDigitCounts(s FiniteSequence, window int) [10]int {
+	if window <= 0 {
+		panic("window must be positive")
+	}
+	var result [10]int
+	leading, count := 0, 0
+	for value := range s.Values() {
+		if count == 0 {
+			leading = value
+		}
+		count++
+		if count == window {
+			result[leading]++
+			count = 0
+		}
+	}
+	return result
+}
+
+// LongestRun returns the zero based starting position and the length of
+// the longest run of consecutive digits in s that equal digit. If there
+// is more than one longest run, LongestRun returns the first one. If digit
+// does not appear in s, LongestRun returns (-1, 0).
+func LongestRun(s FiniteSequence, digit int) (position, length int) {
+	position = -1
+	runStart, runLength := -1, 0
+	for index, value := range s.All() {
+		if value != digit || index != runStart+runLength {
+			runLength = 0
+		}
+		if value != digit {
+			continue
+		}
+		if runLength == 0 {
+			runStart = index
+		}
+		runLength++
+		if runLength > length {
+			position, length = runStart, runLength
+		}
+	}
+	return
+}
+
+// TopRuns returns the k longest runs of consecutive digits in s that equal
+// digit. TopRuns sorts the runs by length from longest to shortest and
+// then by position. If s has fewer than k such runs, TopRuns returns all
+// of them.
+func TopRuns(s FiniteSequence, digit, k int) []PositionRange {
+	if k <= 0 {
+		return nil
+	}
+	var runs []PositionRange
+	for index, value := range s.All() {
+		if value != digit {
+			continue
+		}
+		last := len(runs) - 1
+		if last >= 0 && runs[last].End == index {
+			runs[last].End++
+		} else {
+			runs = append(runs, PositionRange{Start: index, End: index + 1})
+		}
+	}
+	slices.SortStableFunc(runs, func(a, b PositionRange) int {
+		return (b.End - b.Start) - (a.End - a.Start)
+	})
+	return runs[:min(k, len(runs))]
+}
+
+func argBest(s FiniteSequence, better func(x, y int) bool) (
+	position, value int) {
+	position, value = -1, -1
+	for index, digit := range s.All() {
+		if position == -1 || better(digit, value) {
+			position, value = index, digit
+		}
+	}
+	return
+}
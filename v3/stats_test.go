@@ -0,0 +1,251 @@
+package sqroot
+
+import (
+	"math"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCountFunc(t *testing.T) {
+	s := Sqrt(2).WithEnd(100)
+	isZero := func(digit int) bool { return digit == 0 }
+	atLeast5 := func(digit int) bool { return digit >= 5 }
+	assert.Equal(t, 10, CountFunc(s, isZero))
+	assert.Equal(t, 54, CountFunc(s, atLeast5))
+	assert.Equal(t, 5, CountFunc(s.FiniteWithStart(50), isZero))
+	assert.Equal(t, 26, CountFunc(s.FiniteWithStart(50), atLeast5))
+	assert.Zero(t, CountFunc(s.FiniteWithStart(100), atLeast5))
+}
+
+func TestDistinctDigits(t *testing.T) {
+	assert.Equal(t, 3, DistinctDigits(Sqrt(100489).WithEnd(100)))
+	assert.Equal(t, 10, DistinctDigits(Sqrt(2).WithEnd(100)))
+	assert.Equal(t, 7, DistinctDigits(Sqrt(2).WithEnd(12)))
+	assert.Equal(t, 2, DistinctDigits(fakeNumber().WithStart(8).WithEnd(10)))
+	assert.Zero(t, DistinctDigits(zeroNumber.WithEnd(100)))
+	assert.Zero(t, DistinctDigits(Sqrt(2).WithEnd(0)))
+}
+
+func TestArgMaxArgMin(t *testing.T) {
+
+	// s = 14142135623730950488
+	s := Sqrt(2).WithEnd(20)
+	position, value := ArgMax(s)
+	assert.Equal(t, 14, position)
+	assert.Equal(t, 9, value)
+	position, value = ArgMin(s)
+	assert.Equal(t, 13, position)
+	assert.Equal(t, 0, value)
+	position, value = ArgMax(s.WithEnd(8))
+	assert.Equal(t, 7, position)
+	assert.Equal(t, 5, value)
+	position, value = ArgMin(s.WithEnd(8))
+	assert.Equal(t, 0, position)
+	assert.Equal(t, 1, value)
+	position, value = ArgMax(s.FiniteWithStart(17))
+	assert.Equal(t, 18, position)
+	assert.Equal(t, 8, value)
+	position, value = ArgMin(s.FiniteWithStart(17))
+	assert.Equal(t, 17, position)
+	assert.Equal(t, 4, value)
+}
+
+func TestArgMaxArgMinEmpty(t *testing.T) {
+	s := Sqrt(2).WithEnd(0)
+	position, value := ArgMax(s)
+	assert.Equal(t, -1, position)
+	assert.Equal(t, -1, value)
+	position, value = ArgMin(s)
+	assert.Equal(t, -1, position)
+	assert.Equal(t, -1, value)
+}
+
+func TestNGramCountsOne(t *testing.T) {
+	s := Sqrt(2).WithEnd(1000)
+	counts := NGramCounts(s, 1)
+	total := 0
+	for digit := 0; digit < 10; digit++ {
+		expected := CountFunc(s, func(d int) bool { return d == digit })
+		assert.Equal(t, expected, counts[string(rune('0'+digit))])
+		total += counts[string(rune('0'+digit))]
+	}
+	assert.Equal(t, 1000, total)
+	assert.Len(t, counts, 10)
+}
+
+func TestNGramCountsTwo(t *testing.T) {
+	n, _ := NewNumberForTesting([]int{1, 1, 2, 1, 1, 1, 2}, nil, 0)
+	counts := NGramCounts(n.WithEnd(7), 2)
+	assert.Equal(t, map[string]int{"11": 3, "12": 2, "21": 1}, counts)
+	assert.Equal(t, map[string]int{"1121112": 1}, NGramCounts(n.WithEnd(7), 7))
+	assert.Empty(t, NGramCounts(n.WithEnd(7), 8))
+	assert.Empty(t, NGramCounts(n.WithEnd(0), 1))
+}
+
+func TestNGramCountsPanics(t *testing.T) {
+	assert.Panics(t, func() { NGramCounts(Sqrt(2).WithEnd(10), 0) })
+}
+
+func TestWindowLeadingDigitCounts(t *testing.T) {
+	n := fakeNumber()
+	assert.Equal(
+		t,
+		[10]int{0, 1, 0, 1, 0, 1, 0, 1, 0, 1},
+		WindowLeadingDigitCounts(n.WithEnd(10), 2))
+	assert.Equal(
+		t,
+		[10]int{1, 1, 0, 1, 1, 0, 1, 1, 0, 1},
+		WindowLeadingDigitCounts(n.WithEnd(22), 3))
+	assert.Equal(
+		t,
+		[10]int{1, 1, 1, 1, 1, 1, 1, 1, 1, 1},
+		WindowLeadingDigitCounts(n.WithEnd(10), 1))
+	assert.Equal(
+		t,
+		[10]int{0, 0, 0, 0, 1, 0, 0, 0, 1, 0},
+		WindowLeadingDigitCounts(n.WithStart(3).WithEnd(12), 4))
+	assert.Equal(t, [10]int{}, WindowLeadingDigitCounts(n.WithEnd(3), 4))
+}
+
+func TestWindowLeadingDigitCountsPanics(t *testing.T) {
+	s := fakeNumber().WithEnd(3)
+	assert.Panics(t, func() { WindowLeadingDigitCounts(s, 0) })
+}
+
+func TestLongestRun(t *testing.T) {
+
+	// n = 0.1002000300002000...
+	n, _ := NewNumberForTesting(
+		[]int{1, 0, 0, 2, 0, 0, 0, 3}, []int{0, 0, 0, 0, 2, 0, 0, 0}, 0)
+	position, length := LongestRun(n.WithEnd(16), 0)
+	assert.Equal(t, 8, position)
+	assert.Equal(t, 4, length)
+	position, length = LongestRun(n.WithEnd(8), 0)
+	assert.Equal(t, 4, position)
+	assert.Equal(t, 3, length)
+	position, length = LongestRun(n.WithEnd(16), 2)
+	assert.Equal(t, 3, position)
+	assert.Equal(t, 1, length)
+	position, length = LongestRun(n.WithEnd(16), 7)
+	assert.Equal(t, -1, position)
+	assert.Equal(t, 0, length)
+}
+
+func TestLongestRunSqrt2(t *testing.T) {
+	s := Sqrt(2).WithEnd(10000)
+	digits := DigitsToString(s)
+	for digit := 0; digit < 10; digit++ {
+		position, length := LongestRun(s, digit)
+		run := strings.Repeat(string(rune('0'+digit)), length)
+		assert.Equal(t, strings.Index(digits, run), position)
+		assert.NotContains(t, digits, run+run[:1])
+	}
+}
+
+func TestLongestRunWithStart(t *testing.T) {
+
+	// n = 0.1002000300002000...
+	n, _ := NewNumberForTesting(
+		[]int{1, 0, 0, 2, 0, 0, 0, 3}, []int{0, 0, 0, 0, 2, 0, 0, 0}, 0)
+	position, length := LongestRun(n.WithStart(10).WithEnd(14), 0)
+	assert.Equal(t, 10, position)
+	assert.Equal(t, 2, length)
+}
+
+func TestLongestRunGaps(t *testing.T) {
+	n, _ := NewNumberForTesting([]int{1, 0, 0, 2, 0, 0, 0, 3}, nil, 0)
+	var pb PositionsBuilder
+	s := pb.AddRange(1, 3).AddRange(4, 6).Build().Filter(n)
+	position, length := LongestRun(s, 0)
+	assert.Equal(t, 1, position)
+	assert.Equal(t, 2, length)
+}
+
+func TestLongestRunEmpty(t *testing.T) {
+	var n FiniteNumber
+	position, length := LongestRun(&n, 0)
+	assert.Equal(t, -1, position)
+	assert.Equal(t, 0, length)
+}
+
+func TestTopRuns(t *testing.T) {
+
+	// n = 0.10020003000020300000400
+	n, _ := NewNumberForTesting(
+		intSliceFromString("10020003000020300000400"), nil, 0)
+	s := n.WithEnd(23)
+	assert.Equal(
+		t,
+		[]PositionRange{
+			{Start: 15, End: 20},
+			{Start: 8, End: 12},
+			{Start: 4, End: 7},
+			{Start: 1, End: 3},
+			{Start: 21, End: 23},
+			{Start: 13, End: 14},
+		},
+		TopRuns(s, 0, 10))
+	assert.Equal(
+		t,
+		[]PositionRange{{Start: 15, End: 20}, {Start: 8, End: 12}},
+		TopRuns(s, 0, 2))
+	assert.Equal(
+		t,
+		[]PositionRange{{Start: 3, End: 4}, {Start: 12, End: 13}},
+		TopRuns(s, 2, 2))
+	assert.Empty(t, TopRuns(s, 9, 3))
+	assert.Empty(t, TopRuns(s, 0, 0))
+}
+
+func TestTopRunsGaps(t *testing.T) {
+	var pb PositionsBuilder
+	pb.AddRange(0, 3).AddRange(4, 6)
+	n, _ := NewNumberForTesting(nil, []int{7}, 0)
+	assert.Equal(
+		t,
+		[]PositionRange{{Start: 0, End: 3}, {Start: 4, End: 6}},
+		TopRuns(pb.Build().Filter(n), 7, 5))
+}
+
+func TestMeanDigit(t *testing.T) {
+
+	// 3 + 1 + 7 = 11 so the mean is 11/3.
+	n, _ := NewFiniteNumber([]int{3, 1, 7}, 3)
+	assert.Equal(t, "3.666666666666666", MeanDigit(n).String())
+	assert.Equal(
+		t,
+		NewNumberFromBigRat(big.NewRat(11, 3)).WithSignificant(1000).Exact(),
+		MeanDigit(n).WithSignificant(1000).Exact())
+
+	// Digits 1 through 9 and then 0 repeat, so the mean of 25 digits is
+	// (45 + 45 + 1+2+3+4+5) / 25 = 105/25 = 4.2.
+	mean := MeanDigit(fakeNumber().WithEnd(25))
+	assert.Equal(t, "4.2", mean.String())
+	value, ok := mean.ExactRat()
+	assert.True(t, ok)
+	assert.Equal(t, big.NewRat(21, 5), value)
+	mean = MeanDigit(Sqrt(2).WithEnd(100))
+	assert.Equal(t, 1, mean.Exponent())
+	assert.Contains(t, []int{4, 5}, mean.At(0))
+	assert.True(t, MeanDigit(Sqrt(2).WithEnd(0)).IsZero())
+	assert.True(t, MeanDigit(zeroNumber).IsZero())
+	assert.True(
+		t, MeanDigit(fakeNumber().WithStart(9).WithEnd(10)).IsZero())
+}
+
+func TestShannonEntropy(t *testing.T) {
+	assert.Equal(t, 0.0, ShannonEntropy(zeroNumber))
+	n, _ := NewFiniteNumber([]int{7, 7, 7, 7}, 0)
+	assert.Equal(t, 0.0, ShannonEntropy(n))
+
+	// Nine 7s and one 3.
+	n, _ = NewFiniteNumber([]int{7, 7, 7, 7, 7, 7, 7, 7, 7, 3}, 0)
+	expected := -(0.9*math.Log10(0.9) + 0.1*math.Log10(0.1))
+	assert.InDelta(t, expected, ShannonEntropy(n), 1e-12)
+	assert.Less(t, ShannonEntropy(n), 0.2)
+	assert.InDelta(t, 1.0, ShannonEntropy(fakeNumber().WithEnd(1000)), 1e-12)
+	assert.InDelta(t, 1.0, ShannonEntropy(Sqrt(2).WithEnd(10000)), 0.001)
+}
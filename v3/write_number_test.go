@@ -1,6 +1,8 @@
 package sqroot
 
 import (
+	"bufio"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -251,6 +253,259 @@ func TestWriteRows11ColumnsShow111(t *testing.T) {
 	assert.Equal(t, expected, actual)
 }
 
+func TestWritePadLastRow(t *testing.T) {
+	actual := Swrite(Sqrt(2).WithEnd(111), PadLastRow(true))
+	expected := `  0  14142 13562 37309 50488 01688 72420 96980 78569 67187 53769
+ 50  48073 17667 97379 90732 47846 21070 38850 38753 43276 41572
+100  73501 38462 3.... ..... ..... ..... ..... ..... ..... .....
+`
+	assert.Equal(t, expected, actual)
+}
+
+func TestWritePadLastRowFullRow(t *testing.T) {
+	n := fakeNumber()
+	actual := Swrite(
+		n.WithEnd(20),
+		DigitsPerRow(10),
+		DigitsPerColumn(0),
+		ShowCount(false),
+		PadLastRow(true))
+	expected := `1234567890
+1234567890
+`
+	assert.Equal(t, expected, actual)
+}
+
+func TestWriteRowNumbers(t *testing.T) {
+	actual := Swrite(Sqrt(2).WithEnd(110), RowNumbers(true))
+	expected := `1  14142 13562 37309 50488 01688 72420 96980 78569 67187 53769
+2  48073 17667 97379 90732 47846 21070 38850 38753 43276 41572
+3  73501 38462
+`
+	assert.Equal(t, expected, actual)
+}
+
+func TestWriteRowNumbersShowCountOff(t *testing.T) {
+	actual := Swrite(
+		fakeNumber().WithEnd(95),
+		RowNumbers(true),
+		ShowCount(false),
+		DigitsPerRow(10),
+		DigitsPerColumn(0))
+	expected := ` 1  1234567890
+ 2  1234567890
+ 3  1234567890
+ 4  1234567890
+ 5  1234567890
+ 6  1234567890
+ 7  1234567890
+ 8  1234567890
+ 9  1234567890
+10  12345
+`
+	assert.Equal(t, expected, actual)
+}
+
+func TestWriteTabAlign(t *testing.T) {
+	actual := Swrite(
+		Sqrt(2).WithEnd(25),
+		TabAlign(true),
+		DigitsPerRow(10),
+		DigitsPerColumn(5))
+	expected := "0\t14142\t13562\n10\t37309\t50488\n20\t01688\n"
+	assert.Equal(t, expected, actual)
+}
+
+func TestWriteTabAlignNoCount(t *testing.T) {
+	actual := Swrite(
+		Sqrt(2).WithEnd(25),
+		TabAlign(true),
+		ShowCount(false),
+		DigitsPerRow(10),
+		DigitsPerColumn(5))
+	expected := "14142\t13562\n37309\t50488\n01688\n"
+	assert.Equal(t, expected, actual)
+	actual = Swrite(
+		Sqrt(2).WithEnd(25),
+		TabAlign(true),
+		ShowCount(false),
+		RowNumbers(true),
+		DigitsPerRow(10),
+		DigitsPerColumn(5))
+	expected = "1\t14142\t13562\n2\t37309\t50488\n3\t01688\n"
+	assert.Equal(t, expected, actual)
+}
+
+func TestWriteVertical(t *testing.T) {
+	actual := Swrite(Sqrt(2).WithEnd(5), Vertical(true), DigitsPerRow(10))
+	expected := `0  1
+1  4
+2  1
+3  4
+4  2
+`
+	assert.Equal(t, expected, actual)
+}
+
+func TestPrintVertical(t *testing.T) {
+	actual := Sprint(Sqrt(2), UpTo(5), Vertical(true))
+	lines := strings.Split(actual, "\n")
+	assert.Equal(t, "0  1", lines[0])
+	assert.Equal(t, "1  4", lines[1])
+	assert.Equal(
+		t,
+		"12  3\n13  0",
+		Sprint(Sqrt(2), Between(12, 14), Vertical(true)))
+}
+
+func TestWriteVerticalCountOffset(t *testing.T) {
+	actual := Swrite(
+		Sqrt(2).WithStart(8).WithEnd(12), Vertical(true), CountOffset(8))
+	expected := `0  6
+1  2
+2  3
+3  7
+`
+	assert.Equal(t, expected, actual)
+}
+
+func TestWriteHighlight(t *testing.T) {
+	var pb PositionsBuilder
+	pb.AddRange(2, 4).Add(7).Add(12)
+	actual := Swrite(
+		Sqrt(2).WithEnd(10),
+		Highlight(pb.Build(), "\x1b[31m"),
+		DigitsPerColumn(3),
+		ShowCount(false))
+	expected := "14\x1b[31m1\x1b[0m \x1b[31m4\x1b[0m21 3\x1b[31m5\x1b[0m6 2\n"
+	assert.Equal(t, expected, actual)
+}
+
+func TestWriteHighlightNone(t *testing.T) {
+	actual := Swrite(
+		Sqrt(2).WithEnd(10), Highlight(Between(20, 30), "\x1b[31m"))
+	assert.Equal(t, Swrite(Sqrt(2).WithEnd(10)), actual)
+}
+
+func TestWritePadLastRowNoRows(t *testing.T) {
+	n := fakeNumber()
+	actual := Swrite(
+		n.WithEnd(12), DigitsPerRow(0), ShowCount(false), PadLastRow(true))
+	assert.Equal(t, "12345 67890 12\n", actual)
+	assert.Equal(t, "\n", Swrite(n.WithEnd(0), PadLastRow(true)))
+}
+
+func TestWriteHeader(t *testing.T) {
+	n := fakeNumber()
+	var sb strings.Builder
+	written, err := Fwrite(
+		&sb, n.WithEnd(25), Header("Digits of n"), DigitsPerRow(10))
+	assert.NoError(t, err)
+	expected := `Digits of n
+ 0  12345 67890
+10  12345 67890
+20  12345
+`
+	assert.Equal(t, expected, sb.String())
+	assert.Equal(t, len(expected), written)
+	assert.Equal(t, 1, strings.Count(sb.String(), "Digits of n"))
+}
+
+func TestWriteHeaderWithStart(t *testing.T) {
+	n := fakeNumber()
+	actual := Swrite(
+		n.WithStart(20).WithEnd(25), Header("sqrt(2)"), DigitsPerRow(10))
+	expected := `sqrt(2)
+20  12345
+`
+	assert.Equal(t, expected, actual)
+}
+
+func TestWriteHeaderNoDigits(t *testing.T) {
+	n := fakeNumber()
+	assert.Equal(t, "Empty\n\n", Swrite(n.WithEnd(0), Header("Empty")))
+}
+
+func TestWriteHeaderError(t *testing.T) {
+	n := fakeNumber()
+	w := &maxBytesWriter{maxBytes: 5}
+	written, err := Fwrite(w, n.WithEnd(25), Header("Digits of n"))
+	assert.Error(t, err)
+	assert.Equal(t, 5, written)
+}
+
+func TestWriteFooter(t *testing.T) {
+	n := fakeNumber()
+	var sb strings.Builder
+	written, err := Fwrite(
+		&sb,
+		n.WithEnd(25),
+		Header("Digits of n"),
+		Footer("sha256:abc"),
+		DigitsPerRow(10))
+	assert.NoError(t, err)
+	expected := `Digits of n
+ 0  12345 67890
+10  12345 67890
+20  12345
+sha256:abc
+`
+	assert.Equal(t, expected, sb.String())
+	assert.Equal(t, len(expected), written)
+	assert.Equal(t, 1, strings.Count(sb.String(), "sha256:abc"))
+}
+
+func TestWriteFooterNoDigits(t *testing.T) {
+	n := fakeNumber()
+	assert.Equal(t, "End\n", Swrite(n.WithEnd(0), Footer("End")))
+	assert.Equal(
+		t,
+		"Start\nEnd\n",
+		Swrite(n.WithEnd(0), Header("Start"), Footer("End")))
+}
+
+func TestWriteFooterError(t *testing.T) {
+	n := fakeNumber()
+	w := &maxBytesWriter{maxBytes: 40}
+	written, err := Fwrite(w, n.WithEnd(25), Footer("Lots of footer text"))
+	assert.Error(t, err)
+	assert.Equal(t, 40, written)
+}
+
+func TestWriteMaxBytes(t *testing.T) {
+	n := Sqrt(2).WithEnd(30)
+	var sb strings.Builder
+	written, err := Fwrite(
+		&sb, n, MaxBytes(40), DigitsPerRow(10), Footer("The end"))
+	assert.NoError(t, err)
+	assert.Equal(t, " 0  14142 13562\n10  37309 50488", sb.String())
+	assert.Equal(t, 31, written)
+	assert.Equal(
+		t,
+		" 0  14142 13562\n10  37309 50488\n20  01688 72420\n",
+		Swrite(n, MaxBytes(48), DigitsPerRow(10)))
+	assert.Equal(
+		t,
+		" 0  14142 13562\n10  37309 50488\n20  01688 72420",
+		Swrite(n, MaxBytes(47), DigitsPerRow(10)))
+	assert.Equal(
+		t,
+		" 0  14142 13562\n10  37309 50488\n20  01688",
+		Swrite(n, MaxBytes(46), DigitsPerRow(10)))
+	assert.Equal(
+		t,
+		"0  1414",
+		Swrite(n, MaxBytes(7), DigitsPerRow(0), DigitsPerColumn(0)))
+	assert.Equal(t, "", Swrite(n, MaxBytes(5), Header("Digits of n")))
+}
+
+func TestWriteMaxBytesError(t *testing.T) {
+	w := &maxBytesWriter{maxBytes: 5}
+	written, err := Fwrite(w, fakeNumber().WithEnd(25), MaxBytes(100))
+	assert.Error(t, err)
+	assert.Equal(t, 5, written)
+}
+
 func TestWriteWithBetween(t *testing.T) {
 	n := fakeNumber()
 	actual := Swrite(
@@ -308,6 +563,74 @@ func TestWriteErrorAtAllStages(t *testing.T) {
 	}
 }
 
+func TestWriteToBufioWriter(t *testing.T) {
+	number := fakeNumber()
+	var sb strings.Builder
+	bw := bufio.NewWriter(&sb)
+	written, err := Fwrite(bw, number.WithEnd(10000), DigitsPerRow(60))
+	assert.NoError(t, err)
+	assert.NoError(t, bw.Flush())
+	expected := Swrite(number.WithEnd(10000), DigitsPerRow(60))
+	assert.Equal(t, expected, sb.String())
+	assert.Equal(t, len(expected), written)
+}
+
+func TestNewBufferedWriterUsesBufioWriter(t *testing.T) {
+	var sb strings.Builder
+	bw := bufio.NewWriter(&sb)
+	writer := newBufferedWriter(&countingWriter{delegate: bw}, 0)
+	assert.Same(t, bw, writer.Writer)
+	writer.WriteString("12")
+	writer.WriteByte('3')
+	writer.WriteRune('4')
+	writer.Write([]byte("56"))
+	assert.Equal(t, 6, writer.cWriter.bytesWritten)
+	assert.Zero(t, writer.Buffered())
+	assert.Equal(t, 6, bw.Buffered())
+	assert.NotSame(
+		t, bw, newBufferedWriter(&countingWriter{delegate: bw}, 1).Writer)
+	small := bufio.NewWriterSize(&sb, 16)
+	assert.NotSame(
+		t, small, newBufferedWriter(&countingWriter{delegate: small}, 0).Writer)
+}
+
+func TestWriteToBufioWriterErrorAtAllStages(t *testing.T) {
+	number := fakeNumber()
+	for i := 0; i < 13200; i += 601 {
+		w := &maxBytesWriter{maxBytes: i}
+		bw := bufio.NewWriter(w)
+		n, err := Fwrite(bw, number.WithEnd(10000))
+		if err == nil {
+			err = bw.Flush()
+		}
+		assert.Error(t, err)
+		assert.Equal(t, i, w.bytesWritten)
+
+		// bw accepts bytes in chunks of its 4096 byte buffer.
+		assert.Equal(t, min((i/4096+1)*4096, 13200), n)
+	}
+}
+
+func TestPrintToBufioWriterLeavesItUnflushed(t *testing.T) {
+	var sb strings.Builder
+	bw := bufio.NewWriter(&sb)
+	n, err := Fprint(bw, Sqrt(2), UpTo(20))
+	assert.NoError(t, err)
+	assert.Equal(t, 25, n)
+	assert.Equal(t, 25, bw.Buffered())
+	assert.Empty(t, sb.String())
+}
+
+func TestPrintToSmallBufioWriterError(t *testing.T) {
+	w := &maxBytesWriter{maxBytes: 10}
+	bw := bufio.NewWriterSize(w, 16)
+	n, err := Fprint(bw, Sqrt(2), UpTo(25))
+	assert.Error(t, err)
+	assert.Equal(t, 10, n)
+	assert.Equal(t, 10, w.bytesWritten)
+	assert.Zero(t, bw.Buffered())
+}
+
 func TestWriteErrorAtAllStages2(t *testing.T) {
 	number := fakeNumber()
 
@@ -319,3 +642,150 @@ func TestWriteErrorAtAllStages2(t *testing.T) {
 		assert.Error(t, err)
 	}
 }
+
+func TestWriteCountOffset(t *testing.T) {
+	s := Sqrt(2).WithStart(500).WithEnd(520)
+	expected := ` 0  35288 50926
+10  48612 49497
+`
+	assert.Equal(t, expected, Swrite(s, DigitsPerRow(10), CountOffset(500)))
+	expected = `500  35288 50926
+510  48612 49497
+`
+	assert.Equal(t, expected, Swrite(s, DigitsPerRow(10)))
+}
+
+func TestWriteCountOffsetNegative(t *testing.T) {
+	actual := Swrite(Sqrt(2).WithEnd(30), DigitsPerRow(10), CountOffset(10))
+	expected := `-10  14142 13562
+  0  37309 50488
+ 10  01688 72420
+`
+	assert.Equal(t, expected, actual)
+}
+
+func TestWriteCSV(t *testing.T) {
+	var builder strings.Builder
+	written, err := FwriteCSV(&builder, Sqrt(2).WithEnd(10), 5)
+	assert.NoError(t, err)
+	expected := "1,4,1,4,2\n1,3,5,6,2\n"
+	assert.Equal(t, expected, builder.String())
+	assert.Equal(t, len(expected), written)
+}
+
+func TestWriteCSVPartialRow(t *testing.T) {
+	var builder strings.Builder
+	FwriteCSV(&builder, Sqrt(2).WithStart(3).WithEnd(10), 4)
+	assert.Equal(t, "4,2,1,3\n5,6,2\n", builder.String())
+}
+
+func TestWriteCSVOneRow(t *testing.T) {
+	var builder strings.Builder
+	FwriteCSV(&builder, Sqrt(2).WithEnd(6), 0)
+	assert.Equal(t, "1,4,1,4,2,1\n", builder.String())
+}
+
+func TestWriteCSVEmpty(t *testing.T) {
+	var builder strings.Builder
+	written, err := FwriteCSV(&builder, Sqrt(2).WithEnd(0), 5)
+	assert.NoError(t, err)
+	assert.Zero(t, written)
+	assert.Empty(t, builder.String())
+}
+
+func TestWriteCSVError(t *testing.T) {
+	w := &maxBytesWriter{maxBytes: 7}
+	written, err := FwriteCSV(w, Sqrt(2).WithEnd(10), 5)
+	assert.Error(t, err)
+	assert.Equal(t, 7, written)
+}
+
+func TestWriteCSVStopsAtFirstError(t *testing.T) {
+	n := Sqrt(2)
+	w := &maxBytesWriter{maxBytes: 100}
+	written, err := FwriteCSV(w, n.WithEnd(100000), 10)
+	assert.Error(t, err)
+	assert.Equal(t, 100, written)
+	count, _ := n.DigitsKnown()
+	assert.Less(t, count, 10000)
+}
+
+func TestWriteTokens(t *testing.T) {
+	tokens := [10]string{
+		"zero", "one", "two", "three", "four",
+		"five", "six", "seven", "eight", "nine"}
+	var sb strings.Builder
+	written, err := FwriteTokens(&sb, Sqrt(7).WithEnd(4), tokens, " ")
+	assert.NoError(t, err)
+	assert.Equal(t, "two six four five", sb.String())
+	assert.Equal(t, len("two six four five"), written)
+}
+
+func TestWriteTokensEmpty(t *testing.T) {
+	var sb strings.Builder
+	var tokens [10]string
+	written, err := FwriteTokens(&sb, Sqrt(7).WithEnd(0), tokens, ",")
+	assert.NoError(t, err)
+	assert.Zero(t, written)
+	assert.Empty(t, sb.String())
+}
+
+func TestWriteTokensNoSep(t *testing.T) {
+	tokens := [10]string{"-----", ".----", "..---", "...--", "....-",
+		".....", "-....", "--...", "---..", "----."}
+	var sb strings.Builder
+	FwriteTokens(&sb, Sqrt(100489).WithEnd(3), tokens, "")
+	assert.Equal(t, "...--.------...", sb.String())
+}
+
+func TestWriteTokensError(t *testing.T) {
+	var tokens [10]string
+	for i := range tokens {
+		tokens[i] = "digit"
+	}
+	w := &maxBytesWriter{maxBytes: 100}
+	written, err := FwriteTokens(w, fakeNumber().WithEnd(10000), tokens, " ")
+	assert.Error(t, err)
+	assert.Equal(t, 100, written)
+}
+
+func TestWriteNumber(t *testing.T) {
+	n := Sqrt(2).WithSignificant(12)
+	var sb strings.Builder
+	written, err := FwriteNumber(&sb, n, 100, DigitsPerRow(10))
+	assert.NoError(t, err)
+	expected := n.String() + `
+ 0  14142 13562
+10  37
+`
+	assert.Equal(t, expected, sb.String())
+	assert.Equal(t, len(expected), written)
+	assert.Equal(t, "1.41421356237", strings.Split(sb.String(), "\n")[0])
+}
+
+func TestWriteNumberNoValue(t *testing.T) {
+	var sb strings.Builder
+	FwriteNumber(&sb, Sqrt(100489), 10, ShowValue(false))
+	assert.Equal(t, "0  317\n", sb.String())
+}
+
+func TestWriteNumberIrrational(t *testing.T) {
+	var sb strings.Builder
+	written, err := FwriteNumber(&sb, Sqrt(3), 12, DigitsPerRow(10))
+	assert.NoError(t, err)
+	expected := `1.73205080756
+ 0  17320 50807
+10  56
+`
+	assert.Equal(t, expected, sb.String())
+	assert.Equal(t, len(expected), written)
+	assert.Panics(t, func() { FwriteNumber(&sb, Sqrt(3), -1) })
+}
+
+func TestWriteShowValue(t *testing.T) {
+	n := Sqrt(2).WithSignificant(5)
+	actual := Swrite(n, ShowValue(true), Header("sqrt(2)"))
+	assert.Equal(t, "sqrt(2)\n1.4142\n0  14142\n", actual)
+	s := n.FiniteWithStart(2)
+	assert.Equal(t, Swrite(s), Swrite(s, ShowValue(true)))
+}